@@ -1,9 +1,14 @@
 package dynamotest
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/pkg/errors"
 )
 
@@ -12,82 +17,346 @@ type TableCleaner interface {
 	CleanTable(tableName string) error
 }
 
-// WholeTableDynamoCleaner removes the table.
+// WholeTableDynamoCleaner removes the table and creates it again, waiting for both operations to complete.
 // While removing it ignores the fact that the table doesn't exist.
-// TODO: Write some tests
 type WholeTableDynamoCleaner struct {
-	dynamoSvc *dynamodb.DynamoDB
+	dynamoSvc dynamodbiface.DynamoDBAPI
+	Waiter    TableWaiter
 }
 
 // NewWholeTableDynamoCleaner creates new instance of WholeTableDynamoCleaner
-func NewWholeTableDynamoCleaner(dynamoSvc *dynamodb.DynamoDB) *WholeTableDynamoCleaner {
-	return &WholeTableDynamoCleaner{dynamoSvc: dynamoSvc}
+func NewWholeTableDynamoCleaner(dynamoSvc dynamodbiface.DynamoDBAPI) *WholeTableDynamoCleaner {
+	return &WholeTableDynamoCleaner{dynamoSvc: dynamoSvc, Waiter: NewDescribeTableWaiter(dynamoSvc)}
 }
 
 func (c *WholeTableDynamoCleaner) CleanTable(tableName string) error {
-	deleteOutput, err := c.dynamoSvc.DeleteTable(&dynamodb.DeleteTableInput{
+	settings, err := c.readTableSettings(tableName)
+	if err != nil {
+		return err
+	}
+	if settings == nil {
+		return nil
+	}
+
+	_, err = c.dynamoSvc.DeleteTable(&dynamodb.DeleteTableInput{
 		TableName: aws.String(tableName),
 	})
-
-	var tableDeleted = true
 	if err != nil {
 		if awsError, ok := err.(awserr.Error); ok && awsError.Code() != dynamodb.ErrCodeResourceNotFoundException || !ok {
 			return errors.Wrapf(err, "migrate: cannot delete table '%s'", tableName)
 		}
-		tableDeleted = false
+		return nil
+	}
+
+	err = c.Waiter.WaitUntilDeleted(tableName)
+	if err != nil {
+		return errors.Wrapf(err, "migrate: table '%s' is still being deleted", tableName)
 	}
 
-	if tableDeleted {
-		createInput := createInputFromDeleteInput(deleteOutput)
-		_, err = c.dynamoSvc.CreateTable(createInput)
+	createInput := CreateTableInputFromDescription(settings.description)
+	if len(settings.tags) > 0 {
+		createInput.Tags = settings.tags
+	}
+	_, err = c.dynamoSvc.CreateTable(createInput)
+	if err != nil {
+		return errors.Wrapf(err, "migrate: cannot recreate table '%s'", tableName)
+	}
+
+	err = c.Waiter.WaitUntilActive(tableName)
+	if err != nil {
+		return errors.Wrapf(err, "migrate: recreated table '%s' is not ready", tableName)
+	}
+
+	if isTimeToLiveEnabled(settings.timeToLive) {
+		_, err = c.dynamoSvc.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+			TableName: aws.String(tableName),
+			TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+				AttributeName: settings.timeToLive.AttributeName,
+				Enabled:       aws.Bool(true),
+			},
+		})
 		if err != nil {
-			return errors.Wrapf(err, "migrate: cannot recreate table '%s'", tableName)
+			return errors.Wrapf(err, "migrate: cannot restore time to live of table '%s'", tableName)
 		}
 	}
 
 	return nil
 }
 
-func createInputFromDeleteInput(d *dynamodb.DeleteTableOutput) *dynamodb.CreateTableInput {
+// tableSettings holds everything needed to recreate a table faithfully
+type tableSettings struct {
+	description *dynamodb.TableDescription
+	timeToLive  *dynamodb.TimeToLiveDescription
+	tags        []*dynamodb.Tag
+}
+
+// readTableSettings reads table settings, returning nil when the table doesn't exist
+func (c *WholeTableDynamoCleaner) readTableSettings(tableName string) (*tableSettings, error) {
+	describeOutput, err := c.dynamoSvc.DescribeTable(&dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		if isAWSErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "migrate: cannot describe table '%s'", tableName)
+	}
+
+	ttlOutput, err := c.dynamoSvc.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "migrate: cannot describe time to live of table '%s'", tableName)
+	}
+
+	settings := &tableSettings{
+		description: describeOutput.Table,
+		timeToLive:  ttlOutput.TimeToLiveDescription,
+	}
+
+	if describeOutput.Table.TableArn == nil {
+		return settings, nil
+	}
+
+	tagsInput := &dynamodb.ListTagsOfResourceInput{ResourceArn: describeOutput.Table.TableArn}
+	for {
+		tagsOutput, err := c.dynamoSvc.ListTagsOfResource(tagsInput)
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrate: cannot list tags of table '%s'", tableName)
+		}
+
+		settings.tags = append(settings.tags, tagsOutput.Tags...)
+		if tagsOutput.NextToken == nil {
+			return settings, nil
+		}
+		tagsInput.NextToken = tagsOutput.NextToken
+	}
+}
+
+// CreateTableInputFromDescription builds CreateTableInput recreating the described table
+// together with its indexes, billing mode, stream and encryption settings.
+// Tags and time to live settings are not part of the description so they have to be restored separately.
+func CreateTableInputFromDescription(d *dynamodb.TableDescription) *dynamodb.CreateTableInput {
+	payPerRequest := isPayPerRequest(d)
 	createInput := dynamodb.CreateTableInput{
-		TableName:            d.TableDescription.TableName,
-		AttributeDefinitions: d.TableDescription.AttributeDefinitions,
-		KeySchema:            d.TableDescription.KeySchema,
-		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-			ReadCapacityUnits:  d.TableDescription.ProvisionedThroughput.ReadCapacityUnits,
-			WriteCapacityUnits: d.TableDescription.ProvisionedThroughput.WriteCapacityUnits,
-		},
-		GlobalSecondaryIndexes: createGSIFromDeletedGSI(d.TableDescription.GlobalSecondaryIndexes),
-		LocalSecondaryIndexes:  createLSIFromDeletedLSI(d.TableDescription.LocalSecondaryIndexes),
+		TableName:              d.TableName,
+		AttributeDefinitions:   d.AttributeDefinitions,
+		KeySchema:              d.KeySchema,
+		GlobalSecondaryIndexes: createGSIFromDescription(d.GlobalSecondaryIndexes, payPerRequest),
+		LocalSecondaryIndexes:  createLSIFromDescription(d.LocalSecondaryIndexes),
+		StreamSpecification:    createStreamSpecificationFromDescription(d.StreamSpecification),
+		SSESpecification:       createSSESpecificationFromDescription(d.SSEDescription),
+	}
+
+	if payPerRequest {
+		createInput.BillingMode = aws.String(dynamodb.BillingModePayPerRequest)
+	} else if d.ProvisionedThroughput != nil {
+		createInput.BillingMode = aws.String(dynamodb.BillingModeProvisioned)
+		createInput.ProvisionedThroughput = createThroughputFromDescription(d.ProvisionedThroughput)
 	}
 
 	return &createInput
 }
 
-func createGSIFromDeletedGSI(gsis []*dynamodb.GlobalSecondaryIndexDescription) []*dynamodb.GlobalSecondaryIndex {
+// isPayPerRequest tells whether the table is on-demand.
+// DynamoDB Local doesn't report billing mode summary, but it reports zero throughput for such tables.
+func isPayPerRequest(d *dynamodb.TableDescription) bool {
+	if d.BillingModeSummary != nil && d.BillingModeSummary.BillingMode != nil {
+		return *d.BillingModeSummary.BillingMode == dynamodb.BillingModePayPerRequest
+	}
+
+	return d.ProvisionedThroughput == nil ||
+		aws.Int64Value(d.ProvisionedThroughput.ReadCapacityUnits) == 0 &&
+			aws.Int64Value(d.ProvisionedThroughput.WriteCapacityUnits) == 0
+}
+
+func createStreamSpecificationFromDescription(s *dynamodb.StreamSpecification) *dynamodb.StreamSpecification {
+	if s == nil || !aws.BoolValue(s.StreamEnabled) {
+		return nil
+	}
+
+	return &dynamodb.StreamSpecification{
+		StreamEnabled:  aws.Bool(true),
+		StreamViewType: s.StreamViewType,
+	}
+}
+
+func createSSESpecificationFromDescription(s *dynamodb.SSEDescription) *dynamodb.SSESpecification {
+	if s == nil {
+		return nil
+	}
+
+	status := aws.StringValue(s.Status)
+	if status != dynamodb.SSEStatusEnabled && status != dynamodb.SSEStatusEnabling {
+		return nil
+	}
+
+	sse := &dynamodb.SSESpecification{
+		Enabled: aws.Bool(true),
+		SSEType: s.SSEType,
+	}
+	if aws.StringValue(s.SSEType) == dynamodb.SSETypeKms {
+		sse.KMSMasterKeyId = s.KMSMasterKeyArn
+	}
+
+	return sse
+}
+
+func isTimeToLiveEnabled(ttl *dynamodb.TimeToLiveDescription) bool {
+	if ttl == nil || ttl.AttributeName == nil {
+		return false
+	}
+
+	status := aws.StringValue(ttl.TimeToLiveStatus)
+	return status == dynamodb.TimeToLiveStatusEnabled || status == dynamodb.TimeToLiveStatusEnabling
+}
+
+// createGSIFromDescription rebuilds global secondary indexes.
+// Indexes of on-demand tables cannot have provisioned throughput, so it's copied for provisioned tables only.
+func createGSIFromDescription(
+	gsis []*dynamodb.GlobalSecondaryIndexDescription,
+	payPerRequest bool,
+) []*dynamodb.GlobalSecondaryIndex {
 	var result []*dynamodb.GlobalSecondaryIndex
 	for _, g := range gsis {
-		var gsi dynamodb.GlobalSecondaryIndex
-		gsi.ProvisionedThroughput.ReadCapacityUnits = g.ProvisionedThroughput.ReadCapacityUnits
-		gsi.ProvisionedThroughput.WriteCapacityUnits = g.ProvisionedThroughput.WriteCapacityUnits
-		gsi.Projection = g.Projection
-		gsi.KeySchema = g.KeySchema
-		gsi.IndexName = g.IndexName
+		gsi := dynamodb.GlobalSecondaryIndex{
+			IndexName:  g.IndexName,
+			KeySchema:  g.KeySchema,
+			Projection: g.Projection,
+		}
+		if !payPerRequest && g.ProvisionedThroughput != nil {
+			gsi.ProvisionedThroughput = createThroughputFromDescription(g.ProvisionedThroughput)
+		}
 		result = append(result, &gsi)
 	}
 
 	return result
 }
 
-func createLSIFromDeletedLSI(lsis []*dynamodb.LocalSecondaryIndexDescription) []*dynamodb.LocalSecondaryIndex {
+func createLSIFromDescription(lsis []*dynamodb.LocalSecondaryIndexDescription) []*dynamodb.LocalSecondaryIndex {
 	var result []*dynamodb.LocalSecondaryIndex
 	for _, l := range lsis {
-		var lsi dynamodb.LocalSecondaryIndex
-		lsi.IndexName = l.IndexName
-		lsi.KeySchema = l.KeySchema
-		lsi.Projection = l.Projection
-		result = append(result, &lsi)
+		result = append(result, &dynamodb.LocalSecondaryIndex{
+			IndexName:  l.IndexName,
+			KeySchema:  l.KeySchema,
+			Projection: l.Projection,
+		})
 	}
 
 	return result
 }
+
+func createThroughputFromDescription(t *dynamodb.ProvisionedThroughputDescription) *dynamodb.ProvisionedThroughput {
+	return &dynamodb.ProvisionedThroughput{
+		ReadCapacityUnits:  t.ReadCapacityUnits,
+		WriteCapacityUnits: t.WriteCapacityUnits,
+	}
+}
+
+const defaultScanSegments = 4
+
+// ScanDeleteTableCleaner removes all items from the table leaving the table itself untouched.
+// It scans the table in parallel segments fetching key attributes only and removes found items in batches.
+// While cleaning it ignores the fact that the table doesn't exist.
+type ScanDeleteTableCleaner struct {
+	dynamoSvc     dynamodbiface.DynamoDBAPI
+	Writer        BatchWriter
+	TotalSegments int
+}
+
+// NewScanDeleteTableCleaner creates new instance of ScanDeleteTableCleaner
+func NewScanDeleteTableCleaner(dynamoSvc dynamodbiface.DynamoDBAPI) *ScanDeleteTableCleaner {
+	return &ScanDeleteTableCleaner{
+		dynamoSvc:     dynamoSvc,
+		Writer:        NewChunkedBatchWriter(dynamoSvc),
+		TotalSegments: defaultScanSegments,
+	}
+}
+
+func (c *ScanDeleteTableCleaner) CleanTable(tableName string) error {
+	describeOutput, err := c.dynamoSvc.DescribeTable(&dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		if isAWSErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+			return nil
+		}
+		return errors.Wrapf(err, "clean: cannot describe table '%s'", tableName)
+	}
+
+	totalSegments := c.TotalSegments
+	if totalSegments < 1 {
+		totalSegments = 1
+	}
+
+	var wg sync.WaitGroup
+	segmentErrors := make([]error, totalSegments)
+	for segment := 0; segment < totalSegments; segment++ {
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			segmentErrors[segment] = c.cleanSegment(describeOutput.Table, segment, totalSegments)
+		}(segment)
+	}
+	wg.Wait()
+
+	for _, err := range segmentErrors {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *ScanDeleteTableCleaner) cleanSegment(table *dynamodb.TableDescription, segment, totalSegments int) error {
+	tableName := aws.StringValue(table.TableName)
+	projection, attributeNames := keyProjection(table.KeySchema)
+	input := &dynamodb.ScanInput{
+		TableName:                table.TableName,
+		ProjectionExpression:     aws.String(projection),
+		ExpressionAttributeNames: attributeNames,
+		Segment:                  aws.Int64(int64(segment)),
+		TotalSegments:            aws.Int64(int64(totalSegments)),
+	}
+
+	for {
+		output, err := c.dynamoSvc.Scan(input)
+		if err != nil {
+			return errors.Wrapf(err, "clean: cannot scan table '%s'", tableName)
+		}
+
+		if len(output.Items) > 0 {
+			requests := make(TableWriteRequests)
+			for _, key := range output.Items {
+				requests[tableName] = append(requests[tableName], &dynamodb.WriteRequest{
+					DeleteRequest: &dynamodb.DeleteRequest{Key: key},
+				})
+			}
+
+			err = c.Writer.WriteBatch(requests)
+			if err != nil {
+				return errors.Wrapf(err, "clean: cannot delete items from table '%s'", tableName)
+			}
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// keyProjection builds a projection expression fetching only key attributes.
+// Attribute names are always aliased as they might be reserved words.
+func keyProjection(keySchema []*dynamodb.KeySchemaElement) (string, map[string]*string) {
+	var placeholders []string
+	attributeNames := make(map[string]*string)
+	for i, k := range keySchema {
+		placeholder := fmt.Sprintf("#k%d", i)
+		placeholders = append(placeholders, placeholder)
+		attributeNames[placeholder] = k.AttributeName
+	}
+
+	return strings.Join(placeholders, ", "), attributeNames
+}
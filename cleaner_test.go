@@ -0,0 +1,329 @@
+package dynamotest_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWholeTableDynamoCleaner(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	_, _ = dynamoSvc.BatchWriteItem(&dynamodb.BatchWriteItemInput{
+		RequestItems: createSampleWriteRequests("tableName", 3),
+	})
+	cleaner := dynamotest.NewWholeTableDynamoCleaner(dynamoSvc)
+
+	err := cleaner.CleanTable("tableName")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"tableName"}, dynamoSvc.tableNames())
+	require.Empty(t, dynamoSvc.items("tableName"))
+}
+
+func TestWholeTableDynamoCleanerWaitsForDeleteAndCreate(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	dynamoSvc.creatingDescribes = 2
+	dynamoSvc.deletingDescribes = 2
+	cleaner := dynamotest.NewWholeTableDynamoCleaner(dynamoSvc)
+	cleaner.Waiter = createFastWaiter(dynamoSvc)
+
+	err := cleaner.CleanTable("tableName")
+
+	require.NoError(t, err)
+	require.True(t, dynamoSvc.tables["tableName"].isActive())
+}
+
+func TestWholeTableDynamoCleanerPreservesTableSettings(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	createInput := createSampleCreateTableInput()
+	createInput.ProvisionedThroughput = nil
+	createInput.BillingMode = aws.String(dynamodb.BillingModePayPerRequest)
+	createInput.StreamSpecification = &dynamodb.StreamSpecification{
+		StreamEnabled:  aws.Bool(true),
+		StreamViewType: aws.String(dynamodb.StreamViewTypeNewAndOldImages),
+	}
+	createInput.SSESpecification = &dynamodb.SSESpecification{
+		Enabled:        aws.Bool(true),
+		SSEType:        aws.String(dynamodb.SSETypeKms),
+		KMSMasterKeyId: aws.String("arn:aws:kms:eu-west-1:123456789012:key/abc"),
+	}
+	createInput.Tags = []*dynamodb.Tag{
+		{Key: aws.String("team"), Value: aws.String("pets")},
+		{Key: aws.String("env"), Value: aws.String("test")},
+	}
+	_, _ = dynamoSvc.CreateTable(createInput)
+	_, _ = dynamoSvc.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String("tableName"),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String("ExpiresAt"),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	before, _ := dynamoSvc.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("tableName")})
+	cleaner := dynamotest.NewWholeTableDynamoCleaner(dynamoSvc)
+
+	err := cleaner.CleanTable("tableName")
+
+	require.NoError(t, err)
+	after, _ := dynamoSvc.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("tableName")})
+	after.Table.CreationDateTime = before.Table.CreationDateTime
+	require.Equal(t, before.Table, after.Table)
+	require.Equal(t, createInput.Tags, dynamoSvc.tables["tableName"].tags)
+	require.Equal(t, "ExpiresAt", aws.StringValue(dynamoSvc.tables["tableName"].timeToLive.AttributeName))
+	require.Equal(
+		t,
+		dynamodb.TimeToLiveStatusEnabled,
+		aws.StringValue(dynamoSvc.tables["tableName"].timeToLive.TimeToLiveStatus),
+	)
+}
+
+func TestWholeTableDynamoCleanerIgnoresMissingTable(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	cleaner := dynamotest.NewWholeTableDynamoCleaner(dynamoSvc)
+
+	err := cleaner.CleanTable("tableName")
+
+	require.NoError(t, err)
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func TestScanDeleteTableCleaner(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	dynamoSvc.scanPageSize = 7
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	writer := dynamotest.NewChunkedBatchWriter(dynamoSvc)
+	_ = writer.WriteBatch(createSampleWriteRequests("tableName", 100))
+	dynamoSvc.rejectWrites = 10
+	cleaner := dynamotest.NewScanDeleteTableCleaner(dynamoSvc)
+	cleaner.Writer = writer
+	writer.BaseDelay = 0
+
+	err := cleaner.CleanTable("tableName")
+
+	require.NoError(t, err)
+	require.Empty(t, dynamoSvc.items("tableName"))
+	require.Equal(t, []string{"tableName"}, dynamoSvc.tableNames())
+	require.True(t, dynamoSvc.scanCalls > cleaner.TotalSegments)
+}
+
+func TestScanDeleteTableCleanerIgnoresMissingTable(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	cleaner := dynamotest.NewScanDeleteTableCleaner(dynamoSvc)
+
+	err := cleaner.CleanTable("tableName")
+
+	require.NoError(t, err)
+	require.Zero(t, dynamoSvc.scanCalls)
+}
+
+func TestWholeTableDynamoCleanerWithIndexes(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	_, _ = dynamoSvc.CreateTable(dynamotest.CreateTableInputFromDescription(createSampleIndexedTableDescription(false)))
+	cleaner := dynamotest.NewWholeTableDynamoCleaner(dynamoSvc)
+
+	err := cleaner.CleanTable("indexedTable")
+
+	require.NoError(t, err)
+	require.Len(t, dynamoSvc.tables["indexedTable"].description.GlobalSecondaryIndexes, 2)
+	require.Len(t, dynamoSvc.tables["indexedTable"].description.LocalSecondaryIndexes, 2)
+}
+
+func TestCreateTableInputFromDescription(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    *dynamodb.DeleteTableOutput
+		expected *dynamodb.CreateTableInput
+	}{
+		{
+			name:     "provisioned table without indexes",
+			input:    &dynamodb.DeleteTableOutput{TableDescription: createSampleTableDescription()},
+			expected: createSampleCreateTableInputWithBillingMode(),
+		},
+		{
+			name:     "provisioned table with indexes",
+			input:    &dynamodb.DeleteTableOutput{TableDescription: createSampleIndexedTableDescription(false)},
+			expected: createSampleIndexedCreateTableInput(false),
+		},
+		{
+			name:     "on-demand table with indexes",
+			input:    &dynamodb.DeleteTableOutput{TableDescription: createSampleIndexedTableDescription(true)},
+			expected: createSampleIndexedCreateTableInput(true),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := dynamotest.CreateTableInputFromDescription(tc.input.TableDescription)
+
+			require.Equal(t, tc.expected, actual)
+			require.NoError(t, actual.Validate())
+		})
+	}
+}
+
+func createSampleTableDescription() *dynamodb.TableDescription {
+	input := createSampleCreateTableInput()
+	return &dynamodb.TableDescription{
+		TableName:            input.TableName,
+		TableStatus:          aws.String(dynamodb.TableStatusDeleting),
+		AttributeDefinitions: input.AttributeDefinitions,
+		KeySchema:            input.KeySchema,
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+			ReadCapacityUnits:      aws.Int64(5),
+			WriteCapacityUnits:     aws.Int64(10),
+			NumberOfDecreasesToday: aws.Int64(0),
+		},
+	}
+}
+
+func createSampleCreateTableInputWithBillingMode() *dynamodb.CreateTableInput {
+	input := createSampleCreateTableInput()
+	input.BillingMode = aws.String(dynamodb.BillingModeProvisioned)
+
+	return input
+}
+
+func createSampleIndexedTableDescription(payPerRequest bool) *dynamodb.TableDescription {
+	throughput := func(read, write int64) *dynamodb.ProvisionedThroughputDescription {
+		if payPerRequest {
+			read, write = 0, 0
+		}
+		return &dynamodb.ProvisionedThroughputDescription{
+			ReadCapacityUnits:      aws.Int64(read),
+			WriteCapacityUnits:     aws.Int64(write),
+			NumberOfDecreasesToday: aws.Int64(0),
+		}
+	}
+
+	description := &dynamodb.TableDescription{
+		TableName:             aws.String("indexedTable"),
+		TableStatus:           aws.String(dynamodb.TableStatusDeleting),
+		AttributeDefinitions:  createSampleIndexedAttributeDefinitions(),
+		KeySchema:             createSampleKeySchema("PK", "SK"),
+		ProvisionedThroughput: throughput(5, 10),
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+			{
+				IndexName:             aws.String("GSI1"),
+				IndexStatus:           aws.String(dynamodb.IndexStatusActive),
+				KeySchema:             createSampleKeySchema("GSI1PK", "GSI1SK"),
+				Projection:            &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+				ProvisionedThroughput: throughput(1, 2),
+				IndexArn:              aws.String("arn:aws:dynamodb:eu-west-1:123456789012:table/indexedTable/index/GSI1"),
+			},
+			{
+				IndexName:   aws.String("GSI2"),
+				IndexStatus: aws.String(dynamodb.IndexStatusActive),
+				KeySchema:   createSampleKeySchema("GSI1SK", ""),
+				Projection: &dynamodb.Projection{
+					ProjectionType:   aws.String(dynamodb.ProjectionTypeInclude),
+					NonKeyAttributes: aws.StringSlice([]string{"Name"}),
+				},
+				ProvisionedThroughput: throughput(3, 4),
+			},
+		},
+		LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndexDescription{
+			{
+				IndexName:  aws.String("LSI1"),
+				KeySchema:  createSampleKeySchema("PK", "LSI1SK"),
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+			},
+			{
+				IndexName:  aws.String("LSI2"),
+				KeySchema:  createSampleKeySchema("PK", "GSI1SK"),
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+			},
+		},
+	}
+	if payPerRequest {
+		description.BillingModeSummary = &dynamodb.BillingModeSummary{
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		}
+	}
+
+	return description
+}
+
+func createSampleIndexedCreateTableInput(payPerRequest bool) *dynamodb.CreateTableInput {
+	throughput := func(read, write int64) *dynamodb.ProvisionedThroughput {
+		if payPerRequest {
+			return nil
+		}
+		return &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(read),
+			WriteCapacityUnits: aws.Int64(write),
+		}
+	}
+
+	input := &dynamodb.CreateTableInput{
+		TableName:             aws.String("indexedTable"),
+		BillingMode:           aws.String(dynamodb.BillingModeProvisioned),
+		AttributeDefinitions:  createSampleIndexedAttributeDefinitions(),
+		KeySchema:             createSampleKeySchema("PK", "SK"),
+		ProvisionedThroughput: throughput(5, 10),
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+			{
+				IndexName:             aws.String("GSI1"),
+				KeySchema:             createSampleKeySchema("GSI1PK", "GSI1SK"),
+				Projection:            &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+				ProvisionedThroughput: throughput(1, 2),
+			},
+			{
+				IndexName: aws.String("GSI2"),
+				KeySchema: createSampleKeySchema("GSI1SK", ""),
+				Projection: &dynamodb.Projection{
+					ProjectionType:   aws.String(dynamodb.ProjectionTypeInclude),
+					NonKeyAttributes: aws.StringSlice([]string{"Name"}),
+				},
+				ProvisionedThroughput: throughput(3, 4),
+			},
+		},
+		LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndex{
+			{
+				IndexName:  aws.String("LSI1"),
+				KeySchema:  createSampleKeySchema("PK", "LSI1SK"),
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+			},
+			{
+				IndexName:  aws.String("LSI2"),
+				KeySchema:  createSampleKeySchema("PK", "GSI1SK"),
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+			},
+		},
+	}
+	if payPerRequest {
+		input.BillingMode = aws.String(dynamodb.BillingModePayPerRequest)
+	}
+
+	return input
+}
+
+func createSampleIndexedAttributeDefinitions() []*dynamodb.AttributeDefinition {
+	var result []*dynamodb.AttributeDefinition
+	for _, name := range []string{"PK", "SK", "GSI1PK", "GSI1SK", "LSI1SK"} {
+		result = append(result, &dynamodb.AttributeDefinition{
+			AttributeName: aws.String(name),
+			AttributeType: aws.String(dynamodb.ScalarAttributeTypeS),
+		})
+	}
+
+	return result
+}
+
+func createSampleKeySchema(hashKey, rangeKey string) []*dynamodb.KeySchemaElement {
+	keySchema := []*dynamodb.KeySchemaElement{
+		{AttributeName: aws.String(hashKey), KeyType: aws.String(dynamodb.KeyTypeHash)},
+	}
+	if rangeKey != "" {
+		keySchema = append(keySchema, &dynamodb.KeySchemaElement{
+			AttributeName: aws.String(rangeKey),
+			KeyType:       aws.String(dynamodb.KeyTypeRange),
+		})
+	}
+
+	return keySchema
+}
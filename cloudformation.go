@@ -0,0 +1,640 @@
+package dynamotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	cloudFormationTableType = "AWS::DynamoDB::Table"
+	samSimpleTableType      = "AWS::Serverless::SimpleTable"
+)
+
+// CloudFormationMigrationDecoder reads tables definitions straight from CloudFormation or SAM templates,
+// in JSON or YAML (recognized by file extension), so migrations don't drift from the infrastructure code.
+// Every AWS::DynamoDB::Table and AWS::Serverless::SimpleTable resource becomes a TableDefinition,
+// named after its TableName property or, when it has none, its logical ID.
+//
+// Intrinsic functions Ref, Fn::Sub and Fn::Join, in full or short YAML form, are resolved using Parameters,
+// falling back to defaults of template parameters. Other intrinsic functions are reported as errors.
+// Resources with a Condition are skipped when it's false, conditions may use Fn::Equals, Fn::Not, Fn::And,
+// Fn::Or and Condition.
+type CloudFormationMigrationDecoder struct {
+	// Parameters are values of template parameters and pseudo parameters, e.g. "Environment" or "AWS::Region"
+	Parameters map[string]string
+}
+
+// NewCloudFormationMigrationDecoder creates CloudFormationMigrationDecoder resolving references with given parameters
+func NewCloudFormationMigrationDecoder(parameters map[string]string) *CloudFormationMigrationDecoder {
+	return &CloudFormationMigrationDecoder{Parameters: parameters}
+}
+
+type cloudFormationTemplate struct {
+	Parameters map[string]struct {
+		Default interface{}
+	}
+	Conditions map[string]interface{}
+	Resources  map[string]struct {
+		Type       string
+		Condition  string
+		Properties map[string]interface{}
+	}
+}
+
+func (d *CloudFormationMigrationDecoder) Decode(input Definition) ([]*TableDefinition, error) {
+	contents := input.Contents
+	if extensionOf(input.Source) != "json" {
+		documents, err := decodeYAMLDocuments(input, "cannot parse template", writeShortFormFunctionAsJSON)
+		if err != nil {
+			return nil, err
+		}
+		if len(documents) != 1 {
+			return nil, &DecodeError{Source: input.Source, Err: errors.New("template must be a single YAML document")}
+		}
+		contents = documents[0].contents
+	}
+
+	var template cloudFormationTemplate
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	if err := decoder.Decode(&template); err != nil {
+		return nil, newJSONDecodeError(input, errors.Wrap(err, "cannot parse template"))
+	}
+
+	resolver := &intrinsicResolver{
+		parameters: make(map[string]string),
+		resources:  make(map[string]bool),
+		conditions: template.Conditions,
+		evaluated:  make(map[string]bool),
+		evaluating: make(map[string]bool),
+	}
+	for name, parameter := range template.Parameters {
+		if parameter.Default != nil {
+			resolver.parameters[name] = parameterDefault(parameter.Default)
+		}
+	}
+	for name, value := range d.Parameters {
+		resolver.parameters[name] = value
+	}
+
+	logicalIDs := make([]string, 0, len(template.Resources))
+	for logicalID, resource := range template.Resources {
+		resolver.resources[logicalID] = true
+		if resource.Type == cloudFormationTableType || resource.Type == samSimpleTableType {
+			logicalIDs = append(logicalIDs, logicalID)
+		}
+	}
+	sort.Strings(logicalIDs)
+
+	var result []*TableDefinition
+	for _, logicalID := range logicalIDs {
+		resource := template.Resources[logicalID]
+		if resource.Condition != "" {
+			created, err := resolver.condition(resource.Condition)
+			if err != nil {
+				return nil, &DecodeError{Source: input.Source, Err: errors.Wrapf(err, "resource '%s'", logicalID)}
+			}
+			if !created {
+				continue
+			}
+		}
+		table, err := d.decodeTable(resolver, logicalID, resource.Type, resource.Properties)
+		if err != nil {
+			return nil, &DecodeError{Source: input.Source, Err: errors.Wrapf(err, "resource '%s'", logicalID)}
+		}
+		result = append(result, table)
+	}
+
+	return result, nil
+}
+
+// writeShortFormFunctionAsJSON expands short form of CloudFormation intrinsic functions, e.g. "!Ref Param",
+// to their full form, e.g. {"Ref": "Param"}
+func writeShortFormFunctionAsJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	functionName := strings.TrimPrefix(node.Tag, "!")
+	key := "Fn::" + functionName
+	if functionName == "Ref" || functionName == "Condition" {
+		key = functionName
+	}
+
+	value := *node
+	value.Tag = ""
+	if functionName == "GetAtt" && value.Kind == yaml.ScalarNode {
+		parts := strings.SplitN(value.Value, ".", 2)
+		value = yaml.Node{Kind: yaml.SequenceNode, Line: node.Line}
+		for _, part := range parts {
+			value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part})
+		}
+	}
+
+	encodedKey, _ := json.Marshal(key)
+	buf.WriteByte('{')
+	buf.Write(encodedKey)
+	buf.WriteByte(':')
+	if err := writeYAMLNodeAsJSON(buf, &value, writeShortFormFunctionAsJSON); err != nil {
+		return err
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+func (d *CloudFormationMigrationDecoder) decodeTable(
+	resolver *intrinsicResolver,
+	logicalID, resourceType string,
+	properties map[string]interface{},
+) (*TableDefinition, error) {
+	resolved, _, err := resolver.resolve(properties)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot encode properties")
+	}
+
+	var table *TableDefinition
+	if resourceType == samSimpleTableType {
+		var simpleTable samSimpleTable
+		if err = json.Unmarshal(encoded, &simpleTable); err != nil {
+			return nil, errors.Wrap(err, "cannot parse properties")
+		}
+		table = simpleTable.tableDefinition()
+	} else {
+		var cfnTable cloudFormationTable
+		if err = json.Unmarshal(encoded, &cfnTable); err != nil {
+			return nil, errors.Wrap(err, "cannot parse properties")
+		}
+		table = cfnTable.tableDefinition()
+	}
+
+	if table.TableName == nil {
+		table.TableName = aws.String(logicalID)
+	}
+
+	return table, nil
+}
+
+// cloudFormationTable holds properties of AWS::DynamoDB::Table resource.
+// Most of them have the same shape as in CreateTableInput, the remaining ones are converted by tableDefinition.
+type cloudFormationTable struct {
+	TableName              *string
+	AttributeDefinitions   []*dynamodb.AttributeDefinition
+	KeySchema              []*dynamodb.KeySchemaElement
+	BillingMode            *string
+	ProvisionedThroughput  *cloudFormationThroughput
+	LocalSecondaryIndexes  []*dynamodb.LocalSecondaryIndex
+	GlobalSecondaryIndexes []struct {
+		IndexName             *string
+		KeySchema             []*dynamodb.KeySchemaElement
+		Projection            *dynamodb.Projection
+		ProvisionedThroughput *cloudFormationThroughput
+	}
+	StreamSpecification *struct {
+		StreamViewType *string
+	}
+	SSESpecification *struct {
+		SSEEnabled     cloudFormationBool
+		SSEType        *string
+		KMSMasterKeyId *string
+	}
+	TimeToLiveSpecification *struct {
+		AttributeName *string
+		Enabled       cloudFormationBool
+	}
+	Tags []*dynamodb.Tag
+}
+
+func (t *cloudFormationTable) tableDefinition() *TableDefinition {
+	input := &dynamodb.CreateTableInput{
+		TableName:             t.TableName,
+		AttributeDefinitions:  t.AttributeDefinitions,
+		KeySchema:             t.KeySchema,
+		BillingMode:           t.BillingMode,
+		ProvisionedThroughput: t.ProvisionedThroughput.provisionedThroughput(),
+		LocalSecondaryIndexes: t.LocalSecondaryIndexes,
+	}
+	for _, gsi := range t.GlobalSecondaryIndexes {
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName:             gsi.IndexName,
+			KeySchema:             gsi.KeySchema,
+			Projection:            gsi.Projection,
+			ProvisionedThroughput: gsi.ProvisionedThroughput.provisionedThroughput(),
+		})
+	}
+	if t.StreamSpecification != nil {
+		input.StreamSpecification = &dynamodb.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: t.StreamSpecification.StreamViewType,
+		}
+	}
+	if t.SSESpecification != nil {
+		input.SSESpecification = &dynamodb.SSESpecification{
+			Enabled:        aws.Bool(bool(t.SSESpecification.SSEEnabled)),
+			SSEType:        t.SSESpecification.SSEType,
+			KMSMasterKeyId: t.SSESpecification.KMSMasterKeyId,
+		}
+	}
+	if len(t.Tags) > 0 {
+		input.Tags = t.Tags
+	}
+
+	table := &TableDefinition{CreateTableInput: input}
+	if t.TimeToLiveSpecification != nil {
+		table.TimeToLiveSpecification = &dynamodb.TimeToLiveSpecification{
+			AttributeName: t.TimeToLiveSpecification.AttributeName,
+			Enabled:       aws.Bool(bool(t.TimeToLiveSpecification.Enabled)),
+		}
+	}
+
+	return table
+}
+
+// samSimpleTable holds properties of AWS::Serverless::SimpleTable resource
+type samSimpleTable struct {
+	TableName  *string
+	PrimaryKey *struct {
+		Name string
+		Type string
+	}
+	ProvisionedThroughput *cloudFormationThroughput
+	SSESpecification      *struct {
+		SSEEnabled cloudFormationBool
+	}
+	Tags map[string]string
+}
+
+// samAttributeTypes maps types of SimpleTable's primary key to DynamoDB attribute types
+var samAttributeTypes = map[string]string{
+	"String": dynamodb.ScalarAttributeTypeS,
+	"Number": dynamodb.ScalarAttributeTypeN,
+	"Binary": dynamodb.ScalarAttributeTypeB,
+}
+
+func (t *samSimpleTable) tableDefinition() *TableDefinition {
+	keyName, keyType := "id", dynamodb.ScalarAttributeTypeS
+	if t.PrimaryKey != nil {
+		keyName = t.PrimaryKey.Name
+		if attributeType, ok := samAttributeTypes[t.PrimaryKey.Type]; ok {
+			keyType = attributeType
+		}
+	}
+
+	input := &dynamodb.CreateTableInput{
+		TableName: t.TableName,
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: aws.String(keyName), AttributeType: aws.String(keyType)},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{AttributeName: aws.String(keyName), KeyType: aws.String(dynamodb.KeyTypeHash)},
+		},
+	}
+	if t.ProvisionedThroughput != nil {
+		input.ProvisionedThroughput = t.ProvisionedThroughput.provisionedThroughput()
+	} else {
+		input.BillingMode = aws.String(dynamodb.BillingModePayPerRequest)
+	}
+	if t.SSESpecification != nil {
+		input.SSESpecification = &dynamodb.SSESpecification{Enabled: aws.Bool(bool(t.SSESpecification.SSEEnabled))}
+	}
+
+	tagKeys := make([]string, 0, len(t.Tags))
+	for key := range t.Tags {
+		tagKeys = append(tagKeys, key)
+	}
+	sort.Strings(tagKeys)
+	for _, key := range tagKeys {
+		input.Tags = append(input.Tags, &dynamodb.Tag{Key: aws.String(key), Value: aws.String(t.Tags[key])})
+	}
+
+	return &TableDefinition{CreateTableInput: input}
+}
+
+type cloudFormationThroughput struct {
+	ReadCapacityUnits  cloudFormationInt
+	WriteCapacityUnits cloudFormationInt
+}
+
+func (t *cloudFormationThroughput) provisionedThroughput() *dynamodb.ProvisionedThroughput {
+	if t == nil {
+		return nil
+	}
+
+	return &dynamodb.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(int64(t.ReadCapacityUnits)),
+		WriteCapacityUnits: aws.Int64(int64(t.WriteCapacityUnits)),
+	}
+}
+
+// cloudFormationInt accepts both numbers and strings, as CloudFormation does
+type cloudFormationInt int64
+
+func (i *cloudFormationInt) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return errors.Errorf("invalid integer: %s", data)
+	}
+	*i = cloudFormationInt(value)
+
+	return nil
+}
+
+// cloudFormationBool accepts both booleans and strings, as CloudFormation does
+type cloudFormationBool bool
+
+func (b *cloudFormationBool) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	if err != nil {
+		return errors.Errorf("invalid boolean: %s", data)
+	}
+	*b = cloudFormationBool(value)
+
+	return nil
+}
+
+// parameterDefault formats the default value of a parameter, lists are comma-delimited like values of List parameters
+func parameterDefault(value interface{}) string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		values = append(values, fmt.Sprint(item))
+	}
+
+	return strings.Join(values, ",")
+}
+
+// intrinsicResolver replaces intrinsic functions of a template with their values
+type intrinsicResolver struct {
+	parameters map[string]string
+	resources  map[string]bool
+	conditions map[string]interface{}
+	// evaluated are values of conditions, evaluating are conditions being evaluated, detecting circular conditions
+	evaluated  map[string]bool
+	evaluating map[string]bool
+}
+
+const noValue = "AWS::NoValue"
+
+// resolve returns the value with all intrinsic functions resolved.
+// The second result is false when the value is a reference to AWS::NoValue and has to be removed.
+func (r *intrinsicResolver) resolve(value interface{}) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 1 {
+			for key, argument := range v {
+				if key == "Ref" || strings.HasPrefix(key, "Fn::") {
+					return r.resolveFunction(key, argument)
+				}
+			}
+		}
+
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, ok, err := r.resolve(item)
+			if err != nil {
+				return nil, false, errors.Wrapf(err, "%s", key)
+			}
+			if ok {
+				result[key] = resolved
+			}
+		}
+		return result, true, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for i, item := range v {
+			resolved, ok, err := r.resolve(item)
+			if err != nil {
+				return nil, false, errors.Wrapf(err, "[%d]", i)
+			}
+			if ok {
+				result = append(result, resolved)
+			}
+		}
+		return result, true, nil
+	default:
+		return value, true, nil
+	}
+}
+
+func (r *intrinsicResolver) resolveFunction(name string, argument interface{}) (interface{}, bool, error) {
+	switch name {
+	case "Ref":
+		reference, ok := argument.(string)
+		if !ok {
+			return nil, false, errors.New("Ref: reference must be a string")
+		}
+		if reference == noValue {
+			return nil, false, nil
+		}
+		value, err := r.reference(reference)
+		return value, true, errors.Wrap(err, "Ref")
+	case "Fn::Sub":
+		value, err := r.sub(argument)
+		return value, true, errors.Wrap(err, "Fn::Sub")
+	case "Fn::Join":
+		value, err := r.join(argument)
+		return value, true, errors.Wrap(err, "Fn::Join")
+	default:
+		return nil, false, errors.Errorf("unsupported intrinsic function %s", name)
+	}
+}
+
+func (r *intrinsicResolver) reference(name string) (string, error) {
+	if value, ok := r.parameters[name]; ok {
+		return value, nil
+	}
+	if r.resources[name] {
+		return "", errors.Errorf("cannot resolve reference to resource '%s'", name)
+	}
+
+	return "", errors.Errorf("missing value of parameter '%s'", name)
+}
+
+var subVariable = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+func (r *intrinsicResolver) sub(argument interface{}) (string, error) {
+	template, variables := "", map[string]interface{}{}
+	switch v := argument.(type) {
+	case string:
+		template = v
+	case []interface{}:
+		if len(v) != 2 {
+			return "", errors.New("expected a string and a map of variables")
+		}
+		var ok bool
+		if template, ok = v[0].(string); !ok {
+			return "", errors.New("expected a string and a map of variables")
+		}
+		if variables, ok = v[1].(map[string]interface{}); !ok {
+			return "", errors.New("expected a string and a map of variables")
+		}
+	default:
+		return "", errors.New("expected a string or a list")
+	}
+
+	var subErr error
+	result := subVariable.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[2 : len(match)-1]
+		if strings.HasPrefix(name, "!") {
+			return "${" + name[1:] + "}"
+		}
+		if variable, ok := variables[name]; ok {
+			value, err := r.resolveString(variable)
+			if err != nil && subErr == nil {
+				subErr = err
+			}
+			return value
+		}
+
+		value, err := r.reference(name)
+		if err != nil && subErr == nil {
+			subErr = err
+		}
+		return value
+	})
+
+	return result, subErr
+}
+
+func (r *intrinsicResolver) join(argument interface{}) (string, error) {
+	arguments, ok := argument.([]interface{})
+	if !ok || len(arguments) != 2 {
+		return "", errors.New("expected a delimiter and a list of values")
+	}
+	delimiter, ok := arguments[0].(string)
+	if !ok {
+		return "", errors.New("delimiter must be a string")
+	}
+	resolvedList, _, err := r.resolve(arguments[1])
+	if err != nil {
+		return "", err
+	}
+	list, ok := resolvedList.([]interface{})
+	if !ok {
+		return "", errors.New("expected a list of values")
+	}
+
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		value, err := r.resolveString(item)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, value)
+	}
+
+	return strings.Join(values, delimiter), nil
+}
+
+func (r *intrinsicResolver) resolveString(value interface{}) (string, error) {
+	resolved, _, err := r.resolve(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := resolved.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", errors.Errorf("expected a string, got %v", resolved)
+	}
+}
+
+// condition evaluates the condition of given name from Conditions section of the template
+func (r *intrinsicResolver) condition(name string) (bool, error) {
+	if value, ok := r.evaluated[name]; ok {
+		return value, nil
+	}
+	definition, ok := r.conditions[name]
+	if !ok {
+		return false, errors.Errorf("condition '%s' is not defined", name)
+	}
+	if r.evaluating[name] {
+		return false, errors.Errorf("condition '%s' refers to itself", name)
+	}
+
+	r.evaluating[name] = true
+	value, err := r.evaluate(definition)
+	delete(r.evaluating, name)
+	if err != nil {
+		return false, errors.Wrapf(err, "condition '%s'", name)
+	}
+	r.evaluated[name] = value
+
+	return value, nil
+}
+
+// evaluate returns the value of a condition function
+func (r *intrinsicResolver) evaluate(value interface{}) (bool, error) {
+	function, ok := value.(map[string]interface{})
+	if !ok || len(function) != 1 {
+		return false, errors.New("expected a condition function")
+	}
+
+	for name, argument := range function {
+		if name == "Condition" {
+			conditionName, ok := argument.(string)
+			if !ok {
+				return false, errors.New("Condition: expected a condition name")
+			}
+			return r.condition(conditionName)
+		}
+
+		arguments, ok := argument.([]interface{})
+		if !ok {
+			return false, errors.Errorf("%s: expected a list of arguments", name)
+		}
+		switch name {
+		case "Fn::Equals":
+			if len(arguments) != 2 {
+				return false, errors.New("Fn::Equals: expected two values")
+			}
+			left, err := r.resolveString(arguments[0])
+			if err != nil {
+				return false, errors.Wrap(err, "Fn::Equals")
+			}
+			right, err := r.resolveString(arguments[1])
+			if err != nil {
+				return false, errors.Wrap(err, "Fn::Equals")
+			}
+			return left == right, nil
+		case "Fn::Not":
+			if len(arguments) != 1 {
+				return false, errors.New("Fn::Not: expected a single condition")
+			}
+			result, err := r.evaluate(arguments[0])
+			return !result, errors.Wrap(err, "Fn::Not")
+		case "Fn::And", "Fn::Or":
+			for _, item := range arguments {
+				result, err := r.evaluate(item)
+				if err != nil {
+					return false, errors.Wrap(err, name)
+				}
+				if result == (name == "Fn::Or") {
+					return result, nil
+				}
+			}
+			return name == "Fn::And", nil
+		default:
+			return false, errors.Errorf("unsupported condition function %s", name)
+		}
+	}
+
+	return false, nil
+}
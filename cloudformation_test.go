@@ -0,0 +1,211 @@
+package dynamotest_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudFormationMigrationDecoder(t *testing.T) {
+	decoder := dynamotest.NewCloudFormationMigrationDecoder(map[string]string{
+		"Environment": "test",
+		"AWS::Region": "eu-west-1",
+	})
+	contents, err := ioutil.ReadFile("test_resources/cloudformation/template.yaml")
+	require.NoError(t, err)
+
+	actual, err := decoder.Decode(definition("test_resources/cloudformation/template.yaml", "template", string(contents)))
+
+	require.NoError(t, err)
+	require.Equal(t, []*dynamotest.TableDefinition{
+		createSampleSimpleTableDefinition(),
+		createSampleCloudFormationTableDefinition(),
+	}, actual)
+}
+
+func TestCloudFormationMigrationDecoderJSONTemplate(t *testing.T) {
+	decoder := dynamotest.NewCloudFormationMigrationDecoder(nil)
+	template := `{
+		"Resources": {
+			"Pets": {
+				"Type": "AWS::DynamoDB::Table",
+				"Properties": {
+					"AttributeDefinitions": [{"AttributeName": "ID", "AttributeType": "N"}],
+					"KeySchema": [{"AttributeName": "ID", "KeyType": "HASH"}],
+					"BillingMode": "PAY_PER_REQUEST"
+				}
+			}
+		}
+	}`
+
+	actual, err := decoder.Decode(definition("template.json", "template", template))
+
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, "Pets", aws.StringValue(actual[0].TableName))
+	require.Equal(t, dynamodb.BillingModePayPerRequest, aws.StringValue(actual[0].BillingMode))
+}
+
+func TestCloudFormationMigrationDecoderMissingParameter(t *testing.T) {
+	decoder := dynamotest.NewCloudFormationMigrationDecoder(nil)
+	template := "Resources:\n  Pets:\n    Type: AWS::DynamoDB::Table\n    Properties:\n      TableName: !Sub pets-${Stage}\n"
+
+	_, err := decoder.Decode(definition("template.yml", "template", template))
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, "template.yml: resource 'Pets': TableName: Fn::Sub: missing value of parameter 'Stage'", err.Error())
+}
+
+func TestCloudFormationMigrationDecoderUnsupportedFunction(t *testing.T) {
+	decoder := dynamotest.NewCloudFormationMigrationDecoder(nil)
+	template := "Resources:\n  Pets:\n    Type: AWS::DynamoDB::Table\n    Properties:\n      TableName: !GetAtt Other.Name\n"
+
+	_, err := decoder.Decode(definition("template.yml", "template", template))
+
+	require.EqualError(t, err, "template.yml: resource 'Pets': TableName: unsupported intrinsic function Fn::GetAtt")
+}
+
+func TestCloudFormationMigrationDecoderConditions(t *testing.T) {
+	template := `Parameters:
+  Stage:
+    Default: dev
+  Regions:
+    Default: [eu-west-1, us-east-1]
+Conditions:
+  IsProd: !Equals [!Ref Stage, prod]
+  IsNotProd: !Not [!Condition IsProd]
+  IsDevInEurope: !And [!Condition IsNotProd, !Equals [!Join [",", [eu-west-1, us-east-1]], !Ref Regions]]
+Resources:
+  Audit:
+    Type: AWS::DynamoDB::Table
+    Condition: IsProd
+    Properties:
+      KeySchema: [{AttributeName: ID, KeyType: HASH}]
+  Pets:
+    Type: AWS::DynamoDB::Table
+    Condition: IsDevInEurope
+    Properties:
+      TableName: !Sub pets-${Regions}
+      KeySchema: [{AttributeName: ID, KeyType: HASH}]
+`
+	testCases := []struct {
+		name       string
+		parameters map[string]string
+		expected   []string
+	}{
+		{name: "defaults", expected: []string{"pets-eu-west-1,us-east-1"}},
+		{name: "prod", parameters: map[string]string{"Stage": "prod"}, expected: []string{"Audit"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoder := dynamotest.NewCloudFormationMigrationDecoder(tc.parameters)
+
+			actual, err := decoder.Decode(definition("template.yml", "template", template))
+
+			require.NoError(t, err)
+			tableNames := make([]string, 0, len(actual))
+			for _, table := range actual {
+				tableNames = append(tableNames, aws.StringValue(table.TableName))
+			}
+			require.Equal(t, tc.expected, tableNames)
+		})
+	}
+}
+
+func TestCloudFormationMigrationDecoderUndefinedCondition(t *testing.T) {
+	decoder := dynamotest.NewCloudFormationMigrationDecoder(nil)
+	template := "Resources:\n  Pets:\n    Type: AWS::DynamoDB::Table\n    Condition: IsProd\n    Properties:\n      TableName: pets\n"
+
+	_, err := decoder.Decode(definition("template.yml", "template", template))
+
+	require.EqualError(t, err, "template.yml: resource 'Pets': condition 'IsProd' is not defined")
+}
+
+func TestDynamoTesterLoadFixturesWithCloudFormationTemplate(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	loader := dynamotest.NewFilesystemDirectoryLoader("test_resources/cloudformation", "yaml")
+	tester := createSampleDynamoTester(dynamoSvc)
+	tester.Migrator.MigrationsLoader = loader
+	tester.Migrator.MigrationsDecoder = dynamotest.NewCloudFormationMigrationDecoder(map[string]string{"AWS::Region": "eu-west-1"})
+	tester.FixturesLoader = staticLoader{
+		"pets": []byte(`{"table": "pets-dev", "items": [{"ID": "rex", "Owner": "john", "ExpiresAt": 1554468913}]}`),
+	}
+
+	err := tester.LoadFixtures()
+
+	require.NoError(t, err)
+	tableName := tester.TableNameFor("pets-dev")
+	require.Len(t, dynamoSvc.items(tableName), 1)
+	ttl, err := dynamoSvc.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: aws.String(tableName)})
+	require.NoError(t, err)
+	require.Equal(t, dynamodb.TimeToLiveStatusEnabled, aws.StringValue(ttl.TimeToLiveDescription.TimeToLiveStatus))
+}
+
+func createSampleCloudFormationTableDefinition() *dynamotest.TableDefinition {
+	return &dynamotest.TableDefinition{
+		CreateTableInput: &dynamodb.CreateTableInput{
+			TableName: aws.String("pets-test"),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("ID"), AttributeType: aws.String("S")},
+				{AttributeName: aws.String("Owner"), AttributeType: aws.String("S")},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("ID"), KeyType: aws.String("HASH")},
+			},
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(5),
+				WriteCapacityUnits: aws.Int64(10),
+			},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+				{
+					IndexName: aws.String("byOwner-test"),
+					KeySchema: []*dynamodb.KeySchemaElement{
+						{AttributeName: aws.String("Owner"), KeyType: aws.String("HASH")},
+					},
+					Projection: &dynamodb.Projection{ProjectionType: aws.String("ALL")},
+					ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+						ReadCapacityUnits:  aws.Int64(1),
+						WriteCapacityUnits: aws.Int64(1),
+					},
+				},
+			},
+			StreamSpecification: &dynamodb.StreamSpecification{
+				StreamEnabled:  aws.Bool(true),
+				StreamViewType: aws.String("NEW_IMAGE"),
+			},
+			SSESpecification: &dynamodb.SSESpecification{Enabled: aws.Bool(true)},
+			Tags: []*dynamodb.Tag{
+				{Key: aws.String("team"), Value: aws.String("pets-eu-west-1")},
+			},
+		},
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String("ExpiresAt"),
+			Enabled:       aws.Bool(true),
+		},
+	}
+}
+
+func createSampleSimpleTableDefinition() *dynamotest.TableDefinition {
+	return &dynamotest.TableDefinition{
+		CreateTableInput: &dynamodb.CreateTableInput{
+			TableName: aws.String("OwnersTable"),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("OwnerID"), AttributeType: aws.String("N")},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("OwnerID"), KeyType: aws.String("HASH")},
+			},
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+			Tags: []*dynamodb.Tag{
+				{Key: aws.String("team"), Value: aws.String("owners")},
+			},
+		},
+	}
+}
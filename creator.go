@@ -1,33 +1,82 @@
 package dynamotest
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/pkg/errors"
 )
 
 // TableCreator defines an interface for structs able to create new DynamoDB table
 type TableCreator interface {
-	CreateTable(input *dynamodb.CreateTableInput) error
+	CreateTable(input *TableDefinition) error
 }
 
-// DefaultTableCreator just creates a table with given CreateTableInput
-// TODO: Write some tests
+// TableCreationReporter is an optional interface of TableCreator telling tables it has created apart from tables
+// that already existed, so Migrator tracks only the former and Teardown keeps the latter.
+// All tables of creators not implementing it are tracked as created.
+type TableCreationReporter interface {
+	CreateTableIfNotExists(input *TableDefinition) (created bool, err error)
+}
+
+// DefaultTableCreator creates a table with given definition, waits until it becomes active
+// and enables its time to live, if requested
 type DefaultTableCreator struct {
-	dynamoSvc *dynamodb.DynamoDB
+	dynamoSvc dynamodbiface.DynamoDBAPI
+	Waiter    TableWaiter
 }
 
 // NewDefaultTableCreator creates new instance of DefaultTableCreator
-func NewDefaultTableCreator(dynamoSvc *dynamodb.DynamoDB) *DefaultTableCreator {
-	return &DefaultTableCreator{dynamoSvc: dynamoSvc}
+func NewDefaultTableCreator(dynamoSvc dynamodbiface.DynamoDBAPI) *DefaultTableCreator {
+	return &DefaultTableCreator{dynamoSvc: dynamoSvc, Waiter: NewDescribeTableWaiter(dynamoSvc)}
+}
+
+func (c *DefaultTableCreator) CreateTable(input *TableDefinition) error {
+	_, err := c.CreateTableIfNotExists(input)
+
+	return err
 }
 
-func (c *DefaultTableCreator) CreateTable(input *dynamodb.CreateTableInput) error {
-	_, err := c.dynamoSvc.CreateTable(input)
+// CreateTableIfNotExists creates the table like CreateTable, it reports false when the table already existed
+func (c *DefaultTableCreator) CreateTableIfNotExists(input *TableDefinition) (bool, error) {
+	created := true
+	_, err := c.dynamoSvc.CreateTable(input.CreateTableInput)
 	if err != nil {
 		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() != dynamodb.ErrCodeResourceInUseException || !ok {
-			return errors.Wrapf(err, "migrate: cannot create table '%s'", *input.TableName)
+			return false, errors.Wrapf(err, "migrate: cannot create table '%s'", *input.TableName)
 		}
+		created = false
+	}
+
+	err = c.Waiter.WaitUntilActive(*input.TableName)
+	if err != nil {
+		return created, errors.Wrapf(err, "migrate: table '%s' is not ready", *input.TableName)
+	}
+
+	return created, c.enableTimeToLive(input)
+}
+
+// enableTimeToLive updates TTL of the table unless it's already enabled, e.g. when the table existed before
+func (c *DefaultTableCreator) enableTimeToLive(input *TableDefinition) error {
+	if input.TimeToLiveSpecification == nil || !aws.BoolValue(input.TimeToLiveSpecification.Enabled) {
+		return nil
+	}
+
+	ttl, err := c.dynamoSvc.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: input.TableName})
+	if err != nil {
+		return errors.Wrapf(err, "migrate: cannot describe time to live of table '%s'", *input.TableName)
+	}
+	if isTimeToLiveEnabled(ttl.TimeToLiveDescription) {
+		return nil
+	}
+
+	_, err = c.dynamoSvc.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName:               input.TableName,
+		TimeToLiveSpecification: input.TimeToLiveSpecification,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "migrate: cannot enable time to live of table '%s'", *input.TableName)
 	}
 
 	return nil
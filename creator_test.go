@@ -0,0 +1,70 @@
+package dynamotest_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTableCreator(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	creator := dynamotest.NewDefaultTableCreator(dynamoSvc)
+
+	created, err := creator.CreateTableIfNotExists(createSampleTableDefinition())
+
+	require.NoError(t, err)
+	require.True(t, created)
+	output, err := dynamoSvc.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("tableName")})
+	require.NoError(t, err)
+	require.Equal(t, createSampleCreateTableInput().KeySchema, output.Table.KeySchema)
+}
+
+func TestDefaultTableCreatorIgnoresExistingTable(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	creator := dynamotest.NewDefaultTableCreator(dynamoSvc)
+
+	created, err := creator.CreateTableIfNotExists(createSampleTableDefinition())
+
+	require.NoError(t, err)
+	require.False(t, created)
+}
+
+func TestDefaultTableCreatorWaitsUntilTableIsActive(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	dynamoSvc.creatingDescribes = 2
+	creator := dynamotest.NewDefaultTableCreator(dynamoSvc)
+	creator.Waiter = createFastWaiter(dynamoSvc)
+
+	err := creator.CreateTable(createSampleTableDefinition())
+
+	require.NoError(t, err)
+	require.True(t, dynamoSvc.tables["tableName"].isActive())
+}
+
+func TestDefaultTableCreatorEnablesTimeToLive(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	creator := dynamotest.NewDefaultTableCreator(dynamoSvc)
+	table := createSampleTableDefinition()
+	table.TimeToLiveSpecification = &dynamodb.TimeToLiveSpecification{
+		AttributeName: aws.String("ExpiresAt"),
+		Enabled:       aws.Bool(true),
+	}
+
+	err := creator.CreateTable(table)
+	require.NoError(t, err)
+	err = creator.CreateTable(table)
+	require.NoError(t, err)
+
+	output, err := dynamoSvc.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: aws.String("tableName")})
+	require.NoError(t, err)
+	require.Equal(t, "ExpiresAt", aws.StringValue(output.TimeToLiveDescription.AttributeName))
+	require.Equal(t, dynamodb.TimeToLiveStatusEnabled, aws.StringValue(output.TimeToLiveDescription.TimeToLiveStatus))
+}
+
+func createSampleTableDefinition() *dynamotest.TableDefinition {
+	return &dynamotest.TableDefinition{CreateTableInput: createSampleCreateTableInput()}
+}
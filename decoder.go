@@ -1,43 +1,153 @@
 package dynamotest
 
 import (
+	"bytes"
 	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/pkg/errors"
 )
 
-// MigrationDecoder defines an interface for unmarshalling raw migrations into DynamoDB's CreateTableInput
+// TableDefinition is a table to be created by a migration.
+// Besides DynamoDB's CreateTableInput it holds settings that cannot be set on table creation.
+type TableDefinition struct {
+	*dynamodb.CreateTableInput
+	// TimeToLiveSpecification is applied once the table is active, nil leaves TTL disabled
+	TimeToLiveSpecification *dynamodb.TimeToLiveSpecification `json:",omitempty"`
+}
+
+// MigrationDecoder defines an interface for unmarshalling raw migrations into tables definitions.
+// A single migration file may define more than one table.
 type MigrationDecoder interface {
-	Decode(input []byte) (*dynamodb.CreateTableInput, error)
+	Decode(input Definition) ([]*TableDefinition, error)
 }
 
-// JSONMigrationDecoder decodes JSON migrations into DynamoDB's CreateTableInput
+// JSONMigrationDecoder decodes JSON migrations, i.e. DynamoDB's CreateTableInput
+// with optional TimeToLiveSpecification, into TableDefinition
 type JSONMigrationDecoder struct {
 }
 
-func (*JSONMigrationDecoder) Decode(input []byte) (*dynamodb.CreateTableInput, error) {
-	var createTable dynamodb.CreateTableInput
-	err := json.Unmarshal(input, &createTable)
+func (*JSONMigrationDecoder) Decode(input Definition) ([]*TableDefinition, error) {
+	table, err := decodeMigration(input.Contents)
+	if err != nil {
+		return nil, newJSONDecodeError(input, err)
+	}
+
+	return []*TableDefinition{table}, nil
+}
+
+// decodeMigration reads either a table definition or a DescribeTable output ({"Table": {...}})
+func decodeMigration(contents []byte) (*TableDefinition, error) {
+	var keys map[string]json.RawMessage
+	err := json.Unmarshal(contents, &keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse migration file")
+	}
+	if description, ok := keys["Table"]; ok && len(keys) == 1 {
+		return decodeTableDescription(description)
+	}
+
+	table := &TableDefinition{CreateTableInput: new(dynamodb.CreateTableInput)}
+	err = json.Unmarshal(contents, table)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse migration file")
+	}
+
+	return table, nil
+}
+
+// decodeTableDescription builds the definition of the table described by DescribeTable output.
+// Timestamps are dropped first, as AWS CLI prints them in formats that cannot be read into time.Time.
+func decodeTableDescription(contents []byte) (*TableDefinition, error) {
+	var description interface{}
+	err := json.Unmarshal(contents, &description)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse table description")
+	}
+	contents, err = json.Marshal(withoutDateTimes(description))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse table description")
+	}
+
+	var tableDescription dynamodb.TableDescription
+	err = json.Unmarshal(contents, &tableDescription)
 	if err != nil {
-		return nil, errors.Wrap(err, "migrate: cannot parse migration file")
+		return nil, errors.Wrap(err, "cannot parse table description")
+	}
+	if tableDescription.TableName == nil {
+		return nil, errors.New("table description has no TableName")
 	}
 
-	return &createTable, nil
+	return &TableDefinition{CreateTableInput: CreateTableInputFromDescription(&tableDescription)}, nil
+}
+
+func withoutDateTimes(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if strings.HasSuffix(key, "DateTime") {
+				delete(v, key)
+				continue
+			}
+			v[key] = withoutDateTimes(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = withoutDateTimes(item)
+		}
+	}
+
+	return value
 }
 
 // TableWriteRequests is a collection of dynamodb.WriteRequest grouped by table
 type TableWriteRequests map[string][]*dynamodb.WriteRequest
 
+// merge appends write requests of other collection, keeping their order
+func (w TableWriteRequests) merge(other TableWriteRequests) {
+	for tableName, requests := range other {
+		w[tableName] = append(w[tableName], requests...)
+	}
+}
+
+// dynamoDBFormat marks fixtures whose items are written in DynamoDB's typed format, e.g. {"ID": {"N": "1"}}
+const dynamoDBFormat = "dynamodb"
+
+// formatMarker is an item attribute overriding the format of the fixture for the item only
+const formatMarker = "$format"
+
+// aliasMarker is an item attribute naming the item, so other items can refer to its attributes, see resolveReferences
+const aliasMarker = "$id"
+
+// operationMarker is an item attribute choosing the Operation applied to the item, OperationPut by default
+const operationMarker = "$op"
+
+// removeMarker lists attributes removed by OperationUpdate
+const removeMarker = "$remove"
+
 type fixture struct {
-	TableName string                   `json:"table"`
-	Items     []map[string]interface{} `json:"items"`
+	TableName string `json:"table"`
+	// Format of items, either plain JSON ("json" or empty) or DynamoDB's typed attribute values ("dynamodb")
+	Format string `json:"format"`
+	// Sets lists attributes of plain JSON items whose arrays are written as string or number sets
+	Sets  []string          `json:"sets"`
+	Items []json.RawMessage `json:"items"`
+	// Include and Extends name other fixtures, they're loaded by DynamoTester, see fixtureHeader
+	Include []string `json:"include"`
+	Extends string   `json:"extends"`
 }
 
-// FixturesDecoder defines an interface of collection of fixtures contents which writes to TableWriteRequests
+// FixturesDecoder defines an interface of collection of fixtures contents which writes to TableWriteRequests.
+// Decoders of fixtures with updates implement OperationsDecoder as well.
 type FixturesDecoder interface {
-	Decode(input [][]byte) (TableWriteRequests, error)
+	Decode(input []Definition) (TableWriteRequests, error)
 }
 
 type JSONFixturesDecoder struct {
@@ -47,28 +157,510 @@ func NewJSONFixturesDecoder() *JSONFixturesDecoder {
 	return &JSONFixturesDecoder{}
 }
 
-func (*JSONFixturesDecoder) Decode(input [][]byte) (TableWriteRequests, error) {
-	writeRequests := make(TableWriteRequests)
-	for _, fixtureContents := range input {
-		var fx fixture
-		err := json.Unmarshal(fixtureContents, &fx)
+func (d *JSONFixturesDecoder) Decode(input []Definition) (TableWriteRequests, error) {
+	return decodeWriteRequests(d, input)
+}
+
+func (*JSONFixturesDecoder) DecodeOperations(input []Definition) (TableOperations, error) {
+	operations := make(TableOperations)
+	for _, fixtureDefinition := range input {
+		fx, err := parseFixture(fixtureDefinition.Contents)
+		if err != nil {
+			return nil, newJSONDecodeError(fixtureDefinition, errors.Wrap(err, "cannot parse fixture"))
+		}
+
+		err = fx.appendTo(operations)
 		if err != nil {
-			return nil, errors.Wrap(err, "fixtures: cannot parse fixture")
+			return nil, &DecodeError{Source: fixtureDefinition.Source, Err: err}
 		}
+	}
+
+	return operations, nil
+}
+
+// decodeWriteRequests decodes operations of fixtures as write requests, for decoders used as FixturesDecoder
+func decodeWriteRequests(decoder OperationsDecoder, input []Definition) (TableWriteRequests, error) {
+	operations, err := decoder.DecodeOperations(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return operations.WriteRequests()
+}
+
+// fixtureDocument is a parsed fixture able to append its operations to the collection
+type fixtureDocument interface {
+	appendTo(operations TableOperations) error
+}
+
+// parseFixture reads a fixture, or a batch write request items, i.e. "RequestItems" of BatchWriteItem input
+// or contents of file passed to "aws dynamodb batch-write-item --request-items"
+func parseFixture(contents []byte) (fixtureDocument, error) {
+	var keys map[string]json.RawMessage
+	err := json.Unmarshal(contents, &keys)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, fixtureItems := range fx.Items {
-			m, err := dynamodbattribute.MarshalMap(fixtureItems)
+	if _, ok := keys["RequestItems"]; ok {
+		var input struct {
+			RequestItems requestItems
+		}
+		err = json.Unmarshal(contents, &input)
+		return input.RequestItems, err
+	}
+
+	if isRequestItems(keys) {
+		var items requestItems
+		err = json.Unmarshal(contents, &items)
+		return items, err
+	}
+
+	fx := new(fixture)
+	err = json.Unmarshal(contents, fx)
+	if err != nil {
+		return nil, err
+	}
+	// fixtures only including or extending other fixtures don't have items on their own
+	if fx.TableName == "" && (fx.Items != nil || (fx.Include == nil && fx.Extends == "")) {
+		return nil, errors.New("fixture must name its table with non-empty 'table'")
+	}
+
+	return fx, nil
+}
+
+// isRequestItems tells whether the document is shaped as request items, i.e. all its values are lists of objects,
+// some of them with PutRequest or DeleteRequest. Documents without any request are request items only when none
+// of their keys is a key of a fixture, so tables named like them, e.g. "items", aren't mistaken for fixtures.
+func isRequestItems(keys map[string]json.RawMessage) bool {
+	hasRequest := false
+	hasFixtureKey := false
+	for key, value := range keys {
+		var requests []map[string]json.RawMessage
+		if json.Unmarshal(value, &requests) != nil {
+			return false
+		}
+		for _, request := range requests {
+			_, isPut := request["PutRequest"]
+			_, isDelete := request["DeleteRequest"]
+			hasRequest = hasRequest || isPut || isDelete
+		}
+		switch key {
+		case "table", "format", "sets", "items", "include", "extends":
+			hasFixtureKey = true
+		}
+	}
+
+	return hasRequest || !hasFixtureKey
+}
+
+// requestItems are write requests, written in typed format, grouped by table
+type requestItems map[string][]*dynamodb.WriteRequest
+
+func (r requestItems) appendTo(operations TableOperations) error {
+	tableNames := make([]string, 0, len(r))
+	for tableName := range r {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		for i, writeRequest := range r[tableName] {
+			err := validateWriteRequest(writeRequest)
 			if err != nil {
-				return nil, errors.Wrap(err, "fixtures: cannot marshal map")
+				return errors.Wrapf(err, "invalid request %d of table '%s'", i, tableName)
 			}
-			writeRequest := &dynamodb.WriteRequest{
-				PutRequest: &dynamodb.PutRequest{
-					Item: m,
-				},
-			}
-			writeRequests[fx.TableName] = append(writeRequests[fx.TableName], writeRequest)
 		}
+		operations.merge(OperationsFromWriteRequests(TableWriteRequests{tableName: r[tableName]}))
+	}
+
+	return nil
+}
+
+func validateWriteRequest(writeRequest *dynamodb.WriteRequest) error {
+	var attributes map[string]*dynamodb.AttributeValue
+	switch {
+	case writeRequest == nil:
+		return errors.New("request cannot be null")
+	case writeRequest.PutRequest != nil && writeRequest.DeleteRequest != nil:
+		return errors.New("request must have either PutRequest or DeleteRequest")
+	case writeRequest.PutRequest != nil:
+		attributes = writeRequest.PutRequest.Item
+	case writeRequest.DeleteRequest != nil:
+		attributes = writeRequest.DeleteRequest.Key
+	default:
+		return errors.New("request must have either PutRequest or DeleteRequest")
+	}
+	if len(attributes) == 0 {
+		return errors.New("request has no attributes")
+	}
+
+	for name, value := range attributes {
+		if err := validateAttributeValue(value); err != nil {
+			return errors.Wrapf(err, "attribute '%s'", name)
+		}
+	}
+
+	return nil
+}
+
+func (fx *fixture) appendTo(operations TableOperations) error {
+	for i, rawItem := range fx.Items {
+		operation, err := fx.operation(rawItem)
+		if err != nil {
+			return errors.Wrapf(err, "cannot marshal item %d", i)
+		}
+		operations[fx.TableName] = append(operations[fx.TableName], operation)
+	}
+
+	return nil
+}
+
+// operation marshals the item into the operation chosen with "$op" attribute
+func (fx *fixture) operation(rawItem json.RawMessage) (*Operation, error) {
+	var attributes map[string]json.RawMessage
+	err := json.Unmarshal(rawItem, &attributes)
+	if err != nil {
+		return nil, err
 	}
 
-	return writeRequests, nil
+	operation := OperationPut
+	if marker, ok := attributes[operationMarker]; ok {
+		if err = json.Unmarshal(marker, &operation); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", operationMarker)
+		}
+		delete(attributes, operationMarker)
+	}
+	var remove []string
+	if marker, ok := attributes[removeMarker]; ok {
+		if operation != OperationUpdate {
+			return nil, errors.Errorf("%s can be used only with %s operation", removeMarker, OperationUpdate)
+		}
+		if err = json.Unmarshal(marker, &remove); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", removeMarker)
+		}
+		delete(attributes, removeMarker)
+	}
+	var alias string
+	if marker, ok := attributes[aliasMarker]; ok {
+		if operation == OperationDelete {
+			return nil, errors.Errorf("%s cannot be used with %s operation", aliasMarker, OperationDelete)
+		}
+		if err = json.Unmarshal(marker, &alias); err != nil || alias == "" {
+			return nil, errors.Errorf("%s must be a non-empty string", aliasMarker)
+		}
+		delete(attributes, aliasMarker)
+	}
+
+	item, err := fx.marshalItem(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation {
+	case OperationPut:
+		return &Operation{Op: OperationPut, Item: item, Alias: alias}, nil
+	case OperationDelete:
+		return &Operation{Op: OperationDelete, Key: item}, nil
+	case OperationUpdate:
+		return &Operation{Op: OperationUpdate, Item: item, Remove: remove, Alias: alias}, nil
+	default:
+		return nil, errors.Errorf("unsupported operation '%s'", operation)
+	}
+}
+
+func (fx *fixture) marshalItem(attributes map[string]json.RawMessage) (map[string]*dynamodb.AttributeValue, error) {
+	format := fx.Format
+	if marker, ok := attributes[formatMarker]; ok {
+		if err := json.Unmarshal(marker, &format); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", formatMarker)
+		}
+		delete(attributes, formatMarker)
+	}
+
+	var item map[string]*dynamodb.AttributeValue
+	var err error
+	switch format {
+	case "", "json":
+		item, err = fx.marshalJSONAttributes(attributes)
+	case dynamoDBFormat:
+		item, err = unmarshalTypedAttributes(attributes)
+	default:
+		return nil, errors.Errorf("unsupported format '%s'", format)
+	}
+
+	return item, err
+}
+
+func (fx *fixture) marshalJSONAttributes(attributes map[string]json.RawMessage) (map[string]*dynamodb.AttributeValue, error) {
+	item := make(map[string]interface{}, len(attributes))
+	for name, rawValue := range attributes {
+		value, err := decodeExactJSON(rawValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "attribute '%s'", name)
+		}
+		item[name] = value
+	}
+
+	m, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range fx.Sets {
+		values, ok := item[name].([]interface{})
+		if !ok {
+			continue
+		}
+		set, err := setFromArray(values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "attribute '%s'", name)
+		}
+		m[name] = set
+	}
+
+	return m, nil
+}
+
+// decodeExactJSON decodes JSON value keeping numbers as dynamodbattribute.Number with their exact text,
+// so they're not rounded to float64 before being marshalled
+func decodeExactJSON(rawValue json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(rawValue))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return exactNumbers(value), nil
+}
+
+func exactNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		return dynamodbattribute.Number(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = exactNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = exactNumbers(item)
+		}
+	}
+
+	return value
+}
+
+// setFromArray builds a string set of array of strings or a number set of array of numbers
+func setFromArray(values []interface{}) (*dynamodb.AttributeValue, error) {
+	if len(values) == 0 {
+		return nil, errors.New("sets cannot be empty")
+	}
+
+	set := new(dynamodb.AttributeValue)
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			set.SS = append(set.SS, aws.String(v))
+		case dynamodbattribute.Number:
+			set.NS = append(set.NS, aws.String(string(v)))
+		default:
+			return nil, errors.Errorf("sets can contain only strings or only numbers, got %v", value)
+		}
+	}
+	if set.SS != nil && set.NS != nil {
+		return nil, errors.New("sets can contain only strings or only numbers")
+	}
+
+	return set, nil
+}
+
+// unmarshalTypedAttributes reads attributes written in DynamoDB's typed format, e.g. {"SS": ["a", "b"]}
+func unmarshalTypedAttributes(attributes map[string]json.RawMessage) (map[string]*dynamodb.AttributeValue, error) {
+	m := make(map[string]*dynamodb.AttributeValue, len(attributes))
+	for name, rawValue := range attributes {
+		var value dynamodb.AttributeValue
+		err := json.Unmarshal(rawValue, &value)
+		if err == nil {
+			err = validateAttributeValue(&value)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "attribute '%s'", name)
+		}
+		m[name] = &value
+	}
+
+	return m, nil
+}
+
+// validateAttributeValue checks that the value, and every nested one, has exactly one type
+func validateAttributeValue(value *dynamodb.AttributeValue) error {
+	if value == nil {
+		return errors.New("attribute value cannot be null, use {\"NULL\": true}")
+	}
+
+	types := 0
+	for _, isSet := range []bool{
+		value.S != nil, value.N != nil, value.B != nil, value.BOOL != nil, value.NULL != nil,
+		value.SS != nil, value.NS != nil, value.BS != nil, value.L != nil, value.M != nil,
+	} {
+		if isSet {
+			types++
+		}
+	}
+	if types != 1 {
+		return errors.New("attribute value must have exactly one of S, N, B, BOOL, NULL, SS, NS, BS, L or M")
+	}
+
+	for i, item := range value.L {
+		if err := validateAttributeValue(item); err != nil {
+			return errors.Wrapf(err, "[%d]", i)
+		}
+	}
+	for name, item := range value.M {
+		if err := validateAttributeValue(item); err != nil {
+			return errors.Wrapf(err, "%s", name)
+		}
+	}
+
+	return nil
+}
+
+// ExtensionMigrationDecoder picks the decoder of a migration by extension of its file, e.g. "json" or "yml"
+type ExtensionMigrationDecoder struct {
+	Decoders map[string]MigrationDecoder
+}
+
+// NewExtensionMigrationDecoder creates ExtensionMigrationDecoder decoding JSON and YAML migrations
+func NewExtensionMigrationDecoder() *ExtensionMigrationDecoder {
+	yamlDecoder := new(YAMLMigrationDecoder)
+	return &ExtensionMigrationDecoder{
+		Decoders: map[string]MigrationDecoder{
+			"json": new(JSONMigrationDecoder),
+			"yml":  yamlDecoder,
+			"yaml": yamlDecoder,
+		},
+	}
+}
+
+func (d *ExtensionMigrationDecoder) Decode(input Definition) ([]*TableDefinition, error) {
+	decoder, ok := d.Decoders[extensionOf(input.Source)]
+	if !ok {
+		return nil, &DecodeError{Source: input.Source, Err: errors.New("unsupported migration format")}
+	}
+
+	return decoder.Decode(input)
+}
+
+// ExtensionFixturesDecoder picks the decoder of every fixture by extension of its file, e.g. "json" or "yml"
+type ExtensionFixturesDecoder struct {
+	Decoders map[string]FixturesDecoder
+}
+
+// NewExtensionFixturesDecoder creates ExtensionFixturesDecoder decoding JSON and YAML fixtures
+func NewExtensionFixturesDecoder() *ExtensionFixturesDecoder {
+	yamlDecoder := NewYAMLFixturesDecoder()
+	return &ExtensionFixturesDecoder{
+		Decoders: map[string]FixturesDecoder{
+			"json": NewJSONFixturesDecoder(),
+			"yml":  yamlDecoder,
+			"yaml": yamlDecoder,
+		},
+	}
+}
+
+func (d *ExtensionFixturesDecoder) Decode(input []Definition) (TableWriteRequests, error) {
+	return decodeWriteRequests(d, input)
+}
+
+func (d *ExtensionFixturesDecoder) DecodeOperations(input []Definition) (TableOperations, error) {
+	operations := make(TableOperations)
+	for _, fixtureDefinition := range input {
+		decoder, ok := d.Decoders[extensionOf(fixtureDefinition.Source)]
+		if !ok {
+			return nil, &DecodeError{Source: fixtureDefinition.Source, Err: errors.New("unsupported fixture format")}
+		}
+
+		decoded, err := decodeOperations(decoder, []Definition{fixtureDefinition})
+		if err != nil {
+			return nil, err
+		}
+		operations.merge(decoded)
+	}
+
+	return operations, nil
+}
+
+// extensionOf returns lowercase extension of the source file without the leading dot
+func extensionOf(source Source) string {
+	return strings.ToLower(strings.TrimPrefix(path.Ext(source.Path), "."))
+}
+
+// DecodeError points at the place of a definition that couldn't be decoded
+type DecodeError struct {
+	Source Source
+	// Line and Column are 1-based position of the error, zero when unknown
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	location := e.Source.Path
+	if location == "" {
+		location = e.Source.Name
+	}
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Line)
+	}
+	if e.Line > 0 && e.Column > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Column)
+	}
+
+	return fmt.Sprintf("%s: %v", location, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DecodeError) Cause() error {
+	return e.Err
+}
+
+// newJSONDecodeError creates DecodeError pointing at the position reported by encoding/json, if there's any
+func newJSONDecodeError(input Definition, err error) *DecodeError {
+	decodeErr := &DecodeError{Source: input.Source, Err: err}
+
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case stderrors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case stderrors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return decodeErr
+	}
+
+	decodeErr.Line, decodeErr.Column = position(input.Contents, offset)
+	return decodeErr
+}
+
+// position converts the number of bytes read before the error into 1-based line and column of the last read byte
+func position(contents []byte, offset int64) (int, int) {
+	if offset > int64(len(contents)) {
+		offset = int64(len(contents))
+	}
+	if offset < 1 {
+		return 1, 1
+	}
+
+	consumed := contents[:offset-1]
+	line := bytes.Count(consumed, []byte("\n")) + 1
+	column := len(consumed) - bytes.LastIndexByte(consumed, '\n')
+
+	return line, column
 }
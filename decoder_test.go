@@ -1,6 +1,9 @@
 package dynamotest_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -12,8 +15,8 @@ import (
 
 func TestJsonMigrationDecoder(t *testing.T) {
 	decoder := new(dynamotest.JSONMigrationDecoder)
-	input := createSampleMigrationBytes()
-	expectedOutput := createSampleCreateTableInput()
+	input := definition("migrations/tableName.json", "tableName", string(createSampleMigrationBytes()))
+	expectedOutput := []*dynamotest.TableDefinition{createSampleTableDefinition()}
 	actualOutput, err := decoder.Decode(input)
 
 	require.NoError(t, err)
@@ -22,16 +25,469 @@ func TestJsonMigrationDecoder(t *testing.T) {
 
 func TestJsonMigrationDecoderInvalidInput(t *testing.T) {
 	decoder := new(dynamotest.JSONMigrationDecoder)
-	input := createSampleInvalidMigrationBytes()
+	input := definition("migrations/tableName.json", "tableName", string(createSampleInvalidMigrationBytes()))
 	_, err := decoder.Decode(input)
 
 	require.Error(t, err)
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, input.Source, decodeErr.Source)
+	require.Equal(t, 10, decodeErr.Line)
+	require.Contains(t, err.Error(), "migrations/tableName.json:10:")
+}
+
+func TestJsonMigrationDecoderTypeErrorPosition(t *testing.T) {
+	decoder := new(dynamotest.JSONMigrationDecoder)
+	input := definition("migrations/tableName.json", "tableName", "{\n  \"TableName\": \"tableName\",\n  \"KeySchema\": 5\n}")
+	_, err := decoder.Decode(input)
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, 3, decodeErr.Line)
+	require.Equal(t, 16, decodeErr.Column)
+	var typeErr *json.UnmarshalTypeError
+	require.True(t, errors.As(err, &typeErr))
 }
 
 func TestJsonFixturesDecoder(t *testing.T) {
 	decoder := dynamotest.NewJSONFixturesDecoder()
-	input := createSampleFixturesBytes()
+	input := createSampleFixtureDefinitions()
+	expected := createSampleWriteRequestMap()
+	actual, err := decoder.Decode(input)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestJsonFixturesDecoderPointsAtInvalidFile(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := append(createSampleFixtureDefinitions(), definition("fixtures/broken.json", "broken", "{\n  \"table\": \"tableName\",\n  \"items\": [}\n}"))
+	_, err := decoder.Decode(input)
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, dynamotest.Source{Path: "fixtures/broken.json", Name: "broken"}, decodeErr.Source)
+	require.Equal(t, 3, decodeErr.Line)
+	require.Equal(t, 13, decodeErr.Column)
+	require.Equal(t, "fixtures/broken.json:3:13: cannot parse fixture: invalid character '}' looking for beginning of value", err.Error())
+}
+
+func TestJsonMigrationDecoderWithTimeToLive(t *testing.T) {
+	decoder := new(dynamotest.JSONMigrationDecoder)
+	contents := `{"TableName": "tableName", "TimeToLiveSpecification": {"AttributeName": "ExpiresAt", "Enabled": true}}`
+
+	actualOutput, err := decoder.Decode(definition("migrations/tableName.json", "tableName", contents))
+
+	require.NoError(t, err)
+	require.Equal(t, []*dynamotest.TableDefinition{
+		{
+			CreateTableInput: &dynamodb.CreateTableInput{TableName: aws.String("tableName")},
+			TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+				AttributeName: aws.String("ExpiresAt"),
+				Enabled:       aws.Bool(true),
+			},
+		},
+	}, actualOutput)
+}
+
+func TestJsonFixturesDecoderWithTypedItems(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/typed.json", "typed", `{
+			"table": "tableName",
+			"format": "dynamodb",
+			"items": [
+				{
+					"ID": {"N": "1"},
+					"Tags": {"SS": ["a", "b"]},
+					"Scores": {"NS": ["1", "2.5"]},
+					"Avatar": {"B": "AQID"},
+					"Deleted": {"NULL": true},
+					"Nested": {"M": {"List": {"L": [{"BOOL": true}, {"BS": ["AQ=="]}]}}}
+				},
+				{"$format": "json", "ID": 2}
+			]
+		}`),
+		definition("fixtures/mixed.json", "mixed", `{
+			"table": "tableName",
+			"items": [
+				{"$format": "dynamodb", "ID": {"N": "3"}}
+			]
+		}`),
+	}
+	expected := dynamotest.TableWriteRequests{
+		"tableName": {
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID":      {N: aws.String("1")},
+				"Tags":    {SS: aws.StringSlice([]string{"a", "b"})},
+				"Scores":  {NS: aws.StringSlice([]string{"1", "2.5"})},
+				"Avatar":  {B: []byte{1, 2, 3}},
+				"Deleted": {NULL: aws.Bool(true)},
+				"Nested": {M: map[string]*dynamodb.AttributeValue{
+					"List": {L: []*dynamodb.AttributeValue{
+						{BOOL: aws.Bool(true)},
+						{BS: [][]byte{{1}}},
+					}},
+				}},
+			}}},
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID": {N: aws.String("2")},
+			}}},
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID": {N: aws.String("3")},
+			}}},
+		},
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestJsonFixturesDecoderInfersSetsOfAnnotatedAttributes(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/sets.json", "sets", `{
+			"table": "tableName",
+			"sets": ["Tags", "Scores"],
+			"items": [{"ID": 1, "Tags": ["a", "b"], "Scores": [1, 2.5], "List": ["a"]}]
+		}`),
+	}
+	expected := dynamotest.TableWriteRequests{
+		"tableName": {
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID":     {N: aws.String("1")},
+				"Tags":   {SS: aws.StringSlice([]string{"a", "b"})},
+				"Scores": {NS: aws.StringSlice([]string{"1", "2.5"})},
+				"List":   {L: []*dynamodb.AttributeValue{{S: aws.String("a")}}},
+			}}},
+		},
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestJsonFixturesDecoderRejectsInvalidTypedItems(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	items := map[string]string{
+		"ambiguous type": `{"ID": {"N": "1", "S": "1"}}`,
+		"missing type":   `{"ID": {}}`,
+		"nested null":    `{"ID": {"L": [null]}}`,
+		"invalid binary": `{"ID": {"B": "not base64!"}}`,
+		"unknown format": `{"$format": "xml", "ID": {"N": "1"}}`,
+	}
+
+	for name, item := range items {
+		t.Run(name, func(t *testing.T) {
+			input := []dynamotest.Definition{
+				definition("fixtures/typed.json", "typed", `{"table": "tableName", "format": "dynamodb", "items": [`+item+`]}`),
+			}
+
+			_, err := decoder.Decode(input)
+
+			var decodeErr *dynamotest.DecodeError
+			require.True(t, errors.As(err, &decodeErr))
+			require.Contains(t, err.Error(), "fixtures/typed.json: cannot marshal item 0")
+		})
+	}
+}
+
+func TestJsonFixturesDecoderPreservesNumbersPrecision(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/numbers.json", "numbers", `{
+			"table": "tableName",
+			"sets": ["Scores"],
+			"items": [{
+				"ID": 12345678901234567890,
+				"Max": 99999999999999999999999999999999999999,
+				"Min": -0.00000000000000000000000000000000000001,
+				"Price": 1234567890123456789012345678.9012345678,
+				"Nested": {"CreatedAt": 1554468913000000001, "History": [0.1, 12345678901234567890123456789012345678]},
+				"Scores": [98765432109876543210987654321098765432, 0.30000000000000000000000000000000000004]
+			}]
+		}`),
+	}
+	expected := dynamotest.TableWriteRequests{
+		"tableName": {
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID":    {N: aws.String("12345678901234567890")},
+				"Max":   {N: aws.String("99999999999999999999999999999999999999")},
+				"Min":   {N: aws.String("-0.00000000000000000000000000000000000001")},
+				"Price": {N: aws.String("1234567890123456789012345678.9012345678")},
+				"Nested": {M: map[string]*dynamodb.AttributeValue{
+					"CreatedAt": {N: aws.String("1554468913000000001")},
+					"History": {L: []*dynamodb.AttributeValue{
+						{N: aws.String("0.1")},
+						{N: aws.String("12345678901234567890123456789012345678")},
+					}},
+				}},
+				"Scores": {NS: aws.StringSlice([]string{
+					"98765432109876543210987654321098765432",
+					"0.30000000000000000000000000000000000004",
+				})},
+			}}},
+		},
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestJsonFixturesDecoderRejectsMixedSets(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/sets.json", "sets", `{"table": "tableName", "sets": ["Tags"], "items": [{"Tags": ["a", 1]}]}`),
+	}
+
+	_, err := decoder.Decode(input)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "attribute 'Tags'")
+}
+
+func TestJsonFixturesDecoderWithOperations(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/scenario.json", "scenario", `{"table": "tableName", "items": [
+			{"$op": "put", "$id": "abc", "ID": 1, "Name": "Abc"},
+			{"$op": "delete", "ID": 2},
+			{"$op": "update", "ID": 3, "Name": "Changed", "$remove": ["Age"]}
+		]}`),
+	}
+	expected := dynamotest.TableOperations{
+		"tableName": {
+			{Op: dynamotest.OperationPut, Item: marshalMap(map[string]interface{}{"ID": 1, "Name": "Abc"}), Alias: "abc"},
+			{Op: dynamotest.OperationDelete, Key: marshalMap(map[string]interface{}{"ID": 2})},
+			{
+				Op:     dynamotest.OperationUpdate,
+				Item:   marshalMap(map[string]interface{}{"ID": 3, "Name": "Changed"}),
+				Remove: []string{"Age"},
+			},
+		},
+	}
+
+	actual, err := decoder.DecodeOperations(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestJsonFixturesDecoderDoesNotWriteAliases(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/users.json", "users", `{"table": "tableName", "items": [{"$id": "alice", "ID": 1}]}`),
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, marshalMap(map[string]interface{}{"ID": 1}), actual["tableName"][0].PutRequest.Item)
+}
+
+func TestJsonFixturesDecoderCannotDecodeUpdatesAsWriteRequests(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/scenario.json", "scenario", `{"table": "tableName", "items": [{"$op": "update", "ID": 3, "Name": "Changed"}]}`),
+	}
+
+	_, err := decoder.Decode(input)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "item 0 of table 'tableName': update operation cannot be written as a write request")
+}
+
+func TestJsonFixturesDecoderRejectsInvalidOperations(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	items := map[string]string{
+		"unknown operation":     `{"$op": "upsert", "ID": 1}`,
+		"remove without update": `{"$op": "put", "ID": 1, "$remove": ["Age"]}`,
+		"invalid remove":        `{"$op": "update", "ID": 1, "$remove": "Age"}`,
+		"aliased delete":        `{"$op": "delete", "$id": "alice", "ID": 1}`,
+	}
+
+	for name, item := range items {
+		t.Run(name, func(t *testing.T) {
+			input := []dynamotest.Definition{
+				definition("fixtures/scenario.json", "scenario", `{"table": "tableName", "items": [`+item+`]}`),
+			}
+
+			_, err := decoder.Decode(input)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "fixtures/scenario.json: cannot marshal item 0")
+		})
+	}
+}
+
+func TestJsonMigrationDecoderWithDescribeTableOutput(t *testing.T) {
+	decoder := new(dynamotest.JSONMigrationDecoder)
+	input := definition("migrations/pets.json", "pets", string(createSampleDescribeTableOutput()))
+
+	actualOutput, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, []*dynamotest.TableDefinition{
+		{
+			CreateTableInput: &dynamodb.CreateTableInput{
+				TableName:   aws.String("pets"),
+				BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+				AttributeDefinitions: []*dynamodb.AttributeDefinition{
+					{AttributeName: aws.String("PK"), AttributeType: aws.String("S")},
+					{AttributeName: aws.String("Owner"), AttributeType: aws.String("S")},
+				},
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{AttributeName: aws.String("PK"), KeyType: aws.String("HASH")},
+				},
+				GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+					{
+						IndexName: aws.String("byOwner"),
+						KeySchema: []*dynamodb.KeySchemaElement{
+							{AttributeName: aws.String("Owner"), KeyType: aws.String("HASH")},
+						},
+						Projection: &dynamodb.Projection{ProjectionType: aws.String("ALL")},
+					},
+				},
+				StreamSpecification: &dynamodb.StreamSpecification{
+					StreamEnabled:  aws.Bool(true),
+					StreamViewType: aws.String("NEW_IMAGE"),
+				},
+			},
+		},
+	}, actualOutput)
+}
+
+func TestJsonFixturesDecoderWithBatchWriteRequestItems(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/batch-write-item.json", "batch-write-item", string(createSampleRequestItems())),
+		definition("fixtures/input.json", "input", `{"RequestItems": {"owners": [{"PutRequest": {"Item": {"ID": {"N": "2"}}}}]}}`),
+	}
+	expected := dynamotest.TableWriteRequests{
+		"pets": {
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"PK":   {S: aws.String("pet_1")},
+				"Tags": {SS: aws.StringSlice([]string{"cute", "fluffy"})},
+			}}},
+			{DeleteRequest: &dynamodb.DeleteRequest{Key: map[string]*dynamodb.AttributeValue{
+				"PK": {S: aws.String("pet_2")},
+			}}},
+		},
+		"owners": {
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID": {N: aws.String("12345678901234567890")},
+			}}},
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID": {N: aws.String("2")},
+			}}},
+		},
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestJsonFixturesDecoderWithBatchWriteRequestsOfTablesNamedLikeFixtureKeys(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/batch.json", "batch", `{
+			"items": [{"PutRequest": {"Item": {"ID": {"N": "1"}}}}],
+			"table": [{"DeleteRequest": {"Key": {"ID": {"N": "2"}}}}]
+		}`),
+	}
+	expected := dynamotest.TableWriteRequests{
+		"items": {{PutRequest: &dynamodb.PutRequest{Item: marshalMap(map[string]interface{}{"ID": 1})}}},
+		"table": {{DeleteRequest: &dynamodb.DeleteRequest{Key: marshalMap(map[string]interface{}{"ID": 2})}}},
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestJsonFixturesDecoderRejectsFixtureWithoutTable(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	fixtures := map[string]string{
+		"missing table": `{"items": [{"ID": 1}]}`,
+		"empty table":   `{"table": "", "items": [{"ID": 1}]}`,
+		"empty fixture": `{"table": ""}`,
+	}
+
+	for name, contents := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			_, err := decoder.Decode([]dynamotest.Definition{definition("fixtures/users.json", "users", contents)})
+
+			var decodeErr *dynamotest.DecodeError
+			require.True(t, errors.As(err, &decodeErr))
+			require.Contains(t, err.Error(), "fixtures/users.json: cannot parse fixture: fixture must name its table")
+		})
+	}
+}
+
+func TestJsonFixturesDecoderRejectsInvalidBatchWriteRequests(t *testing.T) {
+	decoder := dynamotest.NewJSONFixturesDecoder()
+	requests := map[string]string{
+		"no request":       `{}`,
+		"both requests":    `{"PutRequest": {"Item": {"ID": {"N": "1"}}}, "DeleteRequest": {"Key": {"ID": {"N": "1"}}}}`,
+		"empty item":       `{"PutRequest": {"Item": {}}}`,
+		"untyped item":     `{"PutRequest": {"Item": {"ID": {"X": "1"}}}}`,
+		"null request":     `null`,
+		"untyped item key": `{"DeleteRequest": {"Key": {"ID": {}}}}`,
+	}
+
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			input := []dynamotest.Definition{
+				definition("fixtures/batch.json", "batch", `{"pets": [`+request+`]}`),
+			}
+
+			_, err := decoder.Decode(input)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "fixtures/batch.json: invalid request 0 of table 'pets'")
+		})
+	}
+}
+
+func TestExtensionMigrationDecoder(t *testing.T) {
+	decoder := dynamotest.NewExtensionMigrationDecoder()
+	expected := []*dynamotest.TableDefinition{createSampleTableDefinition()}
+
+	fromJSON, err := decoder.Decode(definition("migrations/tableName.json", "tableName", string(createSampleMigrationBytes())))
+	require.NoError(t, err)
+	require.Equal(t, expected, fromJSON)
+
+	fromYAML, err := decoder.Decode(definition("migrations/tableName.YML", "tableName", string(createSampleMigrationYAML())))
+	require.NoError(t, err)
+	require.Equal(t, expected, fromYAML)
+}
+
+func TestExtensionMigrationDecoderUnsupportedFormat(t *testing.T) {
+	decoder := dynamotest.NewExtensionMigrationDecoder()
+	_, err := decoder.Decode(definition("migrations/tableName.xml", "tableName", "<table/>"))
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, "migrations/tableName.xml: unsupported migration format", err.Error())
+}
+
+func TestExtensionFixturesDecoder(t *testing.T) {
+	decoder := dynamotest.NewExtensionFixturesDecoder()
+	jsonFixtures := createSampleFixtureDefinitions()
+	input := []dynamotest.Definition{
+		jsonFixtures[0],
+		definition("fixtures/fixture1.yml", "fixture1", string(createSampleFixturesYAML())),
+	}
 	expected := createSampleWriteRequestMap()
+
 	actual, err := decoder.Decode(input)
 	require.NoError(t, err)
 	require.Equal(t, expected, actual)
@@ -74,6 +530,108 @@ func createSampleInvalidMigrationBytes() []byte {
 	`)
 }
 
+// createSampleDescribeTableOutput returns the output of "aws dynamodb describe-table"
+func createSampleDescribeTableOutput() []byte {
+	return []byte(`{
+    "Table": {
+        "AttributeDefinitions": [
+            {
+                "AttributeName": "PK",
+                "AttributeType": "S"
+            },
+            {
+                "AttributeName": "Owner",
+                "AttributeType": "S"
+            }
+        ],
+        "TableName": "pets",
+        "KeySchema": [
+            {
+                "AttributeName": "PK",
+                "KeyType": "HASH"
+            }
+        ],
+        "TableStatus": "ACTIVE",
+        "CreationDateTime": "2019-04-05T12:55:13.123000+02:00",
+        "ProvisionedThroughput": {
+            "LastIncreaseDateTime": 1554461713.123,
+            "NumberOfDecreasesToday": 0,
+            "ReadCapacityUnits": 0,
+            "WriteCapacityUnits": 0
+        },
+        "TableSizeBytes": 0,
+        "ItemCount": 0,
+        "TableArn": "arn:aws:dynamodb:eu-west-1:123456789012:table/pets",
+        "TableId": "0e9b3a44-2a5c-4b7a-9c0e-1a2b3c4d5e6f",
+        "BillingModeSummary": {
+            "BillingMode": "PAY_PER_REQUEST",
+            "LastUpdateToPayPerRequestDateTime": "2019-04-05T12:55:13.123000+02:00"
+        },
+        "GlobalSecondaryIndexes": [
+            {
+                "IndexName": "byOwner",
+                "KeySchema": [
+                    {
+                        "AttributeName": "Owner",
+                        "KeyType": "HASH"
+                    }
+                ],
+                "Projection": {
+                    "ProjectionType": "ALL"
+                },
+                "IndexStatus": "ACTIVE",
+                "ProvisionedThroughput": {
+                    "NumberOfDecreasesToday": 0,
+                    "ReadCapacityUnits": 0,
+                    "WriteCapacityUnits": 0
+                },
+                "IndexSizeBytes": 0,
+                "ItemCount": 0,
+                "IndexArn": "arn:aws:dynamodb:eu-west-1:123456789012:table/pets/index/byOwner"
+            }
+        ],
+        "StreamSpecification": {
+            "StreamEnabled": true,
+            "StreamViewType": "NEW_IMAGE"
+        },
+        "LatestStreamLabel": "2019-04-05T10:55:13.123",
+        "LatestStreamArn": "arn:aws:dynamodb:eu-west-1:123456789012:table/pets/stream/2019-04-05T10:55:13.123"
+    }
+}`)
+}
+
+// createSampleRequestItems returns the file passed to "aws dynamodb batch-write-item --request-items"
+func createSampleRequestItems() []byte {
+	return []byte(`{
+    "pets": [
+        {
+            "PutRequest": {
+                "Item": {
+                    "PK": {"S": "pet_1"},
+                    "Tags": {"SS": ["cute", "fluffy"]}
+                }
+            }
+        },
+        {
+            "DeleteRequest": {
+                "Key": {
+                    "PK": {"S": "pet_2"}
+                }
+            }
+        }
+    ],
+    "owners": [
+        {
+            "PutRequest": {
+                "Item": {
+                    "ID": {"N": "12345678901234567890"}
+                }
+            }
+        }
+    ]
+}`)
+}
+
 func createSampleCreateTableInput() *dynamodb.CreateTableInput {
 	return &dynamodb.CreateTableInput{
 		TableName: aws.String("tableName"),
@@ -139,6 +697,16 @@ func createSampleFixturesBytes() [][]byte {
 	}
 }
 
+func createSampleFixtureDefinitions() []dynamotest.Definition {
+	var result []dynamotest.Definition
+	for i, contents := range createSampleFixturesBytes() {
+		name := fmt.Sprintf("fixture%d", i)
+		result = append(result, definition("fixtures/"+name+".json", name, string(contents)))
+	}
+
+	return result
+}
+
 func createSampleWriteRequestMap() dynamotest.TableWriteRequests {
 	return dynamotest.TableWriteRequests{
 		"tableName": {
@@ -1,78 +1,299 @@
 package dynamotest
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/pkg/errors"
 )
 
 type DynamoTester struct {
-	dynamoDbSvc       *dynamodb.DynamoDB
+	dynamoDbSvc       dynamodbiface.DynamoDBAPI
 	Migrator          *Migrator
 	FixturesLoader    DefinitionsLoader
 	FixturesDecoder   FixturesDecoder
 	TableNameResolver TableNameResolver
 	Cleaner           TableCleaner
+	BatchWriter       BatchWriter
+	Updater           ItemUpdater
+	Waiter            TableWaiter
+	tb                testing.TB
 }
 
-func NewDefaultDynamoTester(dynamoSvc *dynamodb.DynamoDB, migrationsPath string, fixturesPath string) *DynamoTester {
-	dynamoTester := DynamoTester{
-		dynamoDbSvc:       dynamoSvc,
-		Migrator:          NewDefaultMigrator(dynamoSvc, migrationsPath),
-		FixturesLoader:    NewJSONFilesystemReader(fixturesPath),
-		FixturesDecoder:   NewJSONFixturesDecoder(),
-		TableNameResolver: NewMemoizedTableNameResolver(NewTimestampTableNameResolver(new(RealClock))),
-		Cleaner:           NewWholeTableDynamoCleaner(dynamoSvc),
-	}
-	dynamoTester.Migrator.TableNameResolver = dynamoTester.TableNameResolver
-
-	return &dynamoTester
+// NewDefaultDynamoTester creates DynamoTester reading JSON migrations and fixtures from given directories.
+// Use NewDynamoTester to replace any of its dependencies.
+func NewDefaultDynamoTester(dynamoSvc dynamodbiface.DynamoDBAPI, migrationsPath string, fixturesPath string) *DynamoTester {
+	return buildDynamoTester(dynamoSvc, &options{
+		migrationsLoader: NewJSONFilesystemReader(migrationsPath),
+		fixturesLoader:   NewJSONFilesystemReader(fixturesPath),
+	})
 }
 
+// LoadFixtures loads fixtures of given names, or all of them, into migrated and cleaned tables.
+// References to attributes of aliased items, e.g. {{ref alice.userId}}, are resolved across all loaded fixtures,
+// and items are validated against migrations of their tables before anything is written, see FixturesValidationError.
 func (t *DynamoTester) LoadFixtures(names ...string) error {
-	contents, err := t.FixturesLoader.ReadDefinitions(names...)
+	return t.LoadFixturesWith(names)
+}
+
+// LoadFixturesWith loads fixtures like LoadFixtures, altering them with given options, e.g. WithOverride
+func (t *DynamoTester) LoadFixturesWith(names []string, opts ...LoadOption) error {
+	o := new(loadOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	operations, origins, err := t.decodeFixtures(names)
+	if err != nil {
+		return err
+	}
+	err = applyOverrides(operations, o.overrides)
 	if err != nil {
-		return errors.Wrap(err, "fixtures: cannot load fixture files")
+		return err
+	}
+	err = t.validateFixtures(operations, origins)
+	if err != nil {
+		return err
+	}
+
+	resolvedNames := make(map[string]string, len(operations))
+	for tableName := range operations {
+		resolvedName, err := t.prepareTable(tableName)
+		if err != nil {
+			return err
+		}
+		resolvedNames[tableName] = resolvedName
+	}
+
+	return t.writeOperations(operations, resolvedNames)
+}
+
+// writeOperations applies operations of every table in their order. Puts and deletes are written in batches,
+// a batch is flushed before an operation on a key it already contains, as BatchWriteItem rejects duplicated keys,
+// and before every update, which is applied with Updater.
+func (t *DynamoTester) writeOperations(operations TableOperations, resolvedNames map[string]string) error {
+	tableNames := make([]string, 0, len(operations))
+	for tableName := range operations {
+		tableNames = append(tableNames, tableName)
 	}
+	sort.Strings(tableNames)
 
-	writeRequests, err := t.FixturesDecoder.Decode(contents)
+	batch := make(TableWriteRequests)
+	batchKeys := make(map[string]bool)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := t.BatchWriter.WriteBatch(batch)
+		batch = make(TableWriteRequests)
+		batchKeys = make(map[string]bool)
+		return err
+	}
+
+	for _, tableName := range tableNames {
+		keyNames, keyErr := t.keyNames(tableName)
+		resolvedName := resolvedNames[tableName]
+		for i, operation := range operations[tableName] {
+			if operation.Op == OperationUpdate {
+				err := keyErr
+				if err == nil && t.Updater == nil {
+					err = errors.New("tester has no Updater")
+				}
+				if err != nil {
+					return errors.Wrapf(err, "fixtures: cannot update item %d of table '%s'", i, tableName)
+				}
+				if err := flush(); err != nil {
+					return err
+				}
+				key, attributes := operation.splitUpdate(keyNames)
+				if err := t.Updater.UpdateItem(resolvedName, key, attributes, operation.Remove); err != nil {
+					return err
+				}
+				continue
+			}
+
+			writeRequest, err := operation.writeRequest()
+			if err != nil {
+				return errors.Wrapf(err, "fixtures: cannot write item %d of table '%s'", i, tableName)
+			}
+			if keyErr == nil {
+				key, _ := itemKey(operation.attributes(), keyNames)
+				key = resolvedName + "/" + key
+				if batchKeys[key] {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+				batchKeys[key] = true
+			}
+			batch[resolvedName] = append(batch[resolvedName], writeRequest)
+		}
+	}
+
+	return flush()
+}
+
+// LoadExport loads items of DynamoDB table export into the table named as export's TableName.
+// Data files are streamed and items are written in batches, so exports don't have to fit in memory.
+func (t *DynamoTester) LoadExport(export *Export) error {
+	if export.TableName == "" {
+		return errors.Errorf("fixtures: export of '%s' has no table name", export.Dir)
+	}
+	resolvedName, err := t.prepareTable(export.TableName)
 	if err != nil {
-		return errors.Wrap(err, "fixtures: cannot parse fixture files")
+		return err
 	}
 
-	finalWriteRequests := make(TableWriteRequests)
-	for tableName, w := range writeRequests {
-		migrateErr := t.Migrator.MigrateTables(tableName)
-		if migrateErr != nil {
-			return errors.Wrap(migrateErr, "fixtures: cannot migrate tables")
+	batch := make([]*dynamodb.WriteRequest, 0, exportBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
 		}
+		err := t.BatchWriter.WriteBatch(TableWriteRequests{resolvedName: batch})
+		batch = make([]*dynamodb.WriteRequest, 0, exportBatchSize)
+		return err
+	}
 
-		resolvedName := t.TableNameResolver.Resolve(tableName)
-		finalWriteRequests[resolvedName] = w
-		migrateErr = t.Cleaner.CleanTable(resolvedName)
-		if migrateErr != nil {
-			return errors.Wrap(migrateErr, "fixtures: cannot clean table")
+	// write errors are returned as they are, only errors of reading the export are wrapped
+	var writeErr error
+	err = export.ReadItems(func(item map[string]*dynamodb.AttributeValue) error {
+		batch = append(batch, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+		if len(batch) < exportBatchSize {
+			return nil
 		}
+		writeErr = flush()
+		return writeErr
+	})
+	if writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "fixtures: cannot load export")
+	}
+
+	return flush()
+}
+
+// prepareTable migrates the table, cleans it and waits until it's ready for writes, returning its resolved name.
+// Tables without migrations are reported right away, as they would never become active.
+func (t *DynamoTester) prepareTable(tableName string) (string, error) {
+	_, err := t.Migrator.Definition(tableName)
+	if err != nil {
+		return "", errors.Wrap(err, "fixtures: cannot migrate tables")
+	}
+	err = t.Migrator.MigrateTables(tableName)
+	if err != nil {
+		return "", errors.Wrap(err, "fixtures: cannot migrate tables")
 	}
 
-	batchWriteInput := dynamodb.BatchWriteItemInput{
-		RequestItems: finalWriteRequests,
+	resolvedName := t.TableNameResolver.Resolve(tableName)
+	err = t.Cleaner.CleanTable(resolvedName)
+	if err != nil {
+		return "", errors.Wrap(err, "fixtures: cannot clean table")
 	}
 
-	_, err = t.dynamoDbSvc.BatchWriteItem(&batchWriteInput)
+	err = t.Waiter.WaitUntilActive(resolvedName)
 	if err != nil {
-		return errors.Wrap(err, "fixtures: cannot write items")
+		return "", errors.Wrap(err, "fixtures: table is not ready")
 	}
 
-	return nil
+	return resolvedName, nil
 }
 
+// MustLoadFixtures loads fixtures and panics on failure.
+// Tester created with New fails the test with t.Fatalf instead.
 func (t *DynamoTester) MustLoadFixtures(names ...string) {
-	err := t.LoadFixtures(names...)
-	if err != nil {
-		panic("Cannot load fixtures: " + err.Error())
+	if t.tb != nil {
+		t.tb.Helper()
+	}
+
+	t.mustSucceed(t.LoadFixtures(names...))
+}
+
+// MustLoadFixturesWith loads fixtures with given options and panics on failure, like MustLoadFixtures
+func (t *DynamoTester) MustLoadFixturesWith(names []string, opts ...LoadOption) {
+	if t.tb != nil {
+		t.tb.Helper()
+	}
+
+	t.mustSucceed(t.LoadFixturesWith(names, opts...))
+}
+
+func (t *DynamoTester) mustSucceed(err error) {
+	if err == nil {
+		return
+	}
+	if t.tb != nil {
+		t.tb.Helper()
+		t.tb.Fatalf("Cannot load fixtures: %v", err)
+		return
+	}
+	panic("Cannot load fixtures: " + err.Error())
+}
+
+// Teardown deletes every table created by the tester.
+// It doesn't stop on the first failure, errors of all tables that couldn't be deleted are returned as TeardownError.
+func (t *DynamoTester) Teardown() error {
+	var errs []error
+	for _, tableName := range t.Migrator.CreatedTables() {
+		_, err := t.dynamoDbSvc.DeleteTable(&dynamodb.DeleteTableInput{
+			TableName: aws.String(tableName),
+		})
+		if err != nil && !isAWSErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+			errs = append(errs, errors.Wrapf(err, "teardown: cannot delete table '%s'", tableName))
+			continue
+		}
+
+		t.Migrator.untrackTable(tableName)
+	}
+
+	if len(errs) > 0 {
+		return &TeardownError{Errors: errs}
 	}
+
+	return nil
+}
+
+// Close deletes every table created by the tester, see Teardown
+func (t *DynamoTester) Close() error {
+	return t.Teardown()
+}
+
+// Cleanup registers Teardown to be run when the test and all its subtests complete.
+// Teardown errors are reported as test errors.
+func (t *DynamoTester) Cleanup(tb testing.TB) {
+	tb.Helper()
+	tb.Cleanup(func() {
+		err := t.Teardown()
+		if err != nil {
+			tb.Errorf("%v", err)
+		}
+	})
 }
 
 func (t *DynamoTester) TableNameFor(tableName string) string {
 	return t.TableNameResolver.Resolve(tableName)
 }
+
+// TeardownError collects errors of all tables that couldn't be deleted by Teardown
+type TeardownError struct {
+	Errors []error
+}
+
+func (e *TeardownError) Error() string {
+	return fmt.Sprintf("teardown: %d table(s) not deleted: %s", len(e.Errors), joinErrors(e.Errors))
+}
+
+func joinErrors(errs []error) string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,224 @@
+package dynamotest_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamoTesterLoadFixtures(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+
+	err := tester.LoadFixtures()
+
+	require.NoError(t, err)
+	tableName := tester.TableNameFor("tableName")
+	require.Equal(t, "tableName_1554468913000000001", tableName)
+	require.Equal(t, []string{tableName}, dynamoSvc.tableNames())
+	require.Len(t, dynamoSvc.items(tableName), 3)
+}
+
+func TestDynamoTesterLoadFixturesCleansTable(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+
+	require.NoError(t, tester.LoadFixtures())
+	require.NoError(t, tester.LoadFixtures("first"))
+
+	require.Len(t, dynamoSvc.items(tester.TableNameFor("tableName")), 2)
+}
+
+func TestDynamoTesterLoadFixturesFailsOnUnknownFixture(t *testing.T) {
+	tester := createSampleDynamoTester(newFakeDynamoDB())
+
+	err := tester.LoadFixtures("unknown")
+
+	require.Error(t, err)
+}
+
+func TestDynamoTesterLoadFixturesReportsWriteErrorOnce(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	dynamoSvc.batchWriteErr = errors.New("throttled")
+	tester := createSampleDynamoTester(dynamoSvc)
+
+	err := tester.LoadFixtures()
+
+	require.EqualError(t, err, "fixtures: cannot write items: throttled")
+}
+
+func TestDynamoTesterLoadFixturesOfTableWithoutMigration(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+	tester.Waiter = dynamotest.NewDescribeTableWaiter(dynamoSvc)
+	tester.FixturesLoader = staticLoader{"typo": []byte(`{"table": "tableNam", "items": [{"ID": 1}]}`)}
+
+	err := tester.LoadFixtures()
+
+	var noMigrationErr *dynamotest.NoMigrationError
+	require.True(t, errors.As(err, &noMigrationErr))
+	require.Equal(t, "tableNam", noMigrationErr.TableName)
+	require.EqualError(t, err, "fixtures: cannot migrate tables: migrate: no migration of table 'tableNam'")
+}
+
+func TestDynamoTesterLoadFixturesPointsAtInvalidFixture(t *testing.T) {
+	tester := createSampleDynamoTester(newFakeDynamoDB())
+	tester.FixturesLoader = staticLoader{"broken": []byte(`{"table": "tableName", "items": {}}`)}
+
+	err := tester.LoadFixtures()
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, "broken.json", decodeErr.Source.Path)
+	require.Contains(t, err.Error(), "broken.json:1:")
+}
+
+func TestDynamoTesterTeardown(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+	require.NoError(t, tester.Migrator.MigrateTables())
+	require.Len(t, dynamoSvc.tableNames(), 2)
+
+	err := tester.Teardown()
+
+	require.NoError(t, err)
+	require.Empty(t, dynamoSvc.tableNames())
+	require.Empty(t, tester.Migrator.CreatedTables())
+}
+
+func TestDynamoTesterTeardownKeepsExistingTables(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+	existing := createSampleCreateTableInput()
+	existing.TableName = aws.String(tester.TableNameFor("tableName"))
+	_, err := dynamoSvc.CreateTable(existing)
+	require.NoError(t, err)
+	require.NoError(t, tester.Migrator.MigrateTables())
+
+	err = tester.Teardown()
+
+	require.NoError(t, err)
+	require.Equal(t, []string{tester.TableNameFor("tableName")}, dynamoSvc.tableNames())
+}
+
+func TestDynamoTesterTeardownDropsTablesOfCustomCreator(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+	tester.Migrator.Creator = tableCreatorFunc(func(input *dynamotest.TableDefinition) error {
+		_, err := dynamoSvc.CreateTable(input.CreateTableInput)
+		return err
+	})
+	require.NoError(t, tester.Migrator.MigrateTables())
+
+	err := tester.Teardown()
+
+	require.NoError(t, err)
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func TestMigratorDoesNotKeepDefinitionsOfFailedLoad(t *testing.T) {
+	tester := createSampleDynamoTester(newFakeDynamoDB())
+	tester.Migrator.MigrationsLoader = staticLoader{"a": createSampleMigrationBytes(), "b": createSampleInvalidMigrationBytes()}
+
+	_, err := tester.Migrator.Definition("tableName")
+	require.Error(t, err)
+	tester.Migrator.MigrationsLoader = staticLoader{}
+	_, err = tester.Migrator.Definition("tableName")
+
+	var noMigrationErr *dynamotest.NoMigrationError
+	require.True(t, errors.As(err, &noMigrationErr))
+}
+
+func TestDynamoTesterTeardownCollectsErrors(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+	require.NoError(t, tester.Migrator.MigrateTables())
+	dynamoSvc.deleteTableErrors = map[string]error{
+		tester.TableNameFor("tableName"):  errors.New("access denied"),
+		tester.TableNameFor("otherTable"): errors.New("throttled"),
+	}
+
+	err := tester.Teardown()
+
+	require.IsType(t, &dynamotest.TeardownError{}, err)
+	require.Len(t, err.(*dynamotest.TeardownError).Errors, 2)
+	require.Len(t, tester.Migrator.CreatedTables(), 2)
+
+	dynamoSvc.deleteTableErrors = nil
+	require.NoError(t, tester.Close())
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func TestDynamoTesterCleanup(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	t.Run("subtest", func(t *testing.T) {
+		tester := createSampleDynamoTester(dynamoSvc)
+		tester.Cleanup(t)
+
+		require.NoError(t, tester.LoadFixtures())
+		require.NotEmpty(t, dynamoSvc.tableNames())
+	})
+
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func createSampleDynamoTester(dynamoSvc *fakeDynamoDB) *dynamotest.DynamoTester {
+	tester := dynamotest.NewDefaultDynamoTester(dynamoSvc, "", "")
+	tester.TableNameResolver = dynamotest.NewMemoizedTableNameResolver(
+		dynamotest.NewTimestampTableNameResolver(&dynamotest.FakeClock{
+			FrozenTime: time.Date(2019, 4, 5, 12, 55, 13, 1, time.UTC),
+		}),
+	)
+	tester.Migrator.TableNameResolver = tester.TableNameResolver
+	useSampleDefinitions(tester)
+
+	return tester
+}
+
+func useSampleDefinitions(tester *dynamotest.DynamoTester) {
+	tester.Migrator.MigrationsLoader = staticLoader{
+		"tableName":  createSampleMigrationBytes(),
+		"otherTable": bytes.Replace(createSampleMigrationBytes(), []byte("tableName"), []byte("otherTable"), 1),
+	}
+	fixtures := createSampleFixturesBytes()
+	tester.FixturesLoader = staticLoader{
+		"first":  fixtures[0],
+		"second": fixtures[1],
+	}
+}
+
+// staticLoader is a DefinitionsLoader serving definitions from memory, in alphabetical order of names
+type staticLoader map[string][]byte
+
+func (l staticLoader) ReadDefinitions(names ...string) ([]dynamotest.Definition, error) {
+	if len(names) == 0 {
+		for name := range l {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var result []dynamotest.Definition
+	for _, name := range names {
+		contents, ok := l[name]
+		if !ok {
+			return nil, fmt.Errorf("definition not found: %s", name)
+		}
+		result = append(result, definition(name+".json", name, string(contents)))
+	}
+
+	return result, nil
+}
+
+type tableCreatorFunc func(input *dynamotest.TableDefinition) error
+
+func (f tableCreatorFunc) CreateTable(input *dynamotest.TableDefinition) error {
+	return f(input)
+}
@@ -0,0 +1,205 @@
+package dynamotest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+const (
+	exportSummaryFile  = "manifest-summary.json"
+	exportFilesFile    = "manifest-files.json"
+	exportDataDir      = "data"
+	exportOutputFormat = "DYNAMODB_JSON"
+	// exportBatchSize is the number of items LoadExport keeps in memory before writing them
+	exportBatchSize = 1000
+	// maxExportLineSize is the limit of a single line of data file, large enough for 400KB items in DynamoDB JSON
+	maxExportLineSize = 4 * 1024 * 1024
+)
+
+// Export is a DynamoDB table export to S3 downloaded to the local filesystem, i.e. a directory with
+// manifest-summary.json, manifest-files.json and gzipped JSON lines data files in DynamoDB JSON format.
+type Export struct {
+	// Dir is the directory of the export
+	Dir string
+	// TableName is the name of the exported table read from the manifest.
+	// Change it to load items into another table, e.g. the one used by migrations.
+	TableName string
+	// ItemCount is the number of exported items reported by the manifest
+	ItemCount int64
+	// DataFiles are paths of data files
+	DataFiles []string
+}
+
+type exportSummary struct {
+	TableArn     string `json:"tableArn"`
+	ItemCount    int64  `json:"itemCount"`
+	OutputFormat string `json:"outputFormat"`
+}
+
+type exportDataFile struct {
+	DataFileS3Key string `json:"dataFileS3Key"`
+}
+
+// OpenExport reads manifests of the export in given directory.
+// Data files are expected in "data" subdirectory of the directory, as in the S3 bucket.
+func OpenExport(dir string) (*Export, error) {
+	summaryContents, err := ioutil.ReadFile(filepath.Join(dir, exportSummaryFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "export: cannot read manifest summary of '%s'", dir)
+	}
+	var summary exportSummary
+	err = json.Unmarshal(summaryContents, &summary)
+	if err != nil {
+		return nil, errors.Wrapf(err, "export: cannot parse manifest summary of '%s'", dir)
+	}
+	if summary.OutputFormat != "" && summary.OutputFormat != exportOutputFormat {
+		return nil, errors.Errorf("export: unsupported output format '%s' of '%s'", summary.OutputFormat, dir)
+	}
+
+	export := &Export{
+		Dir:       dir,
+		TableName: tableNameFromArn(summary.TableArn),
+		ItemCount: summary.ItemCount,
+	}
+
+	filesContents, err := ioutil.ReadFile(filepath.Join(dir, exportFilesFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "export: cannot read manifest files of '%s'", dir)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(filesContents))
+	for {
+		var dataFile exportDataFile
+		err = decoder.Decode(&dataFile)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "export: cannot parse manifest files of '%s'", dir)
+		}
+		export.DataFiles = append(export.DataFiles, filepath.Join(dir, exportDataDir, path.Base(dataFile.DataFileS3Key)))
+	}
+
+	return export, nil
+}
+
+// tableNameFromArn reads table name from ARN like "arn:aws:dynamodb:eu-west-1:123456789012:table/pets"
+func tableNameFromArn(arn string) string {
+	i := strings.Index(arn, ":table/")
+	if i < 0 {
+		return ""
+	}
+
+	return strings.SplitN(arn[i+len(":table/"):], "/", 2)[0]
+}
+
+// ReadItems streams items of all data files, one by one, to given function
+func (e *Export) ReadItems(fn func(item map[string]*dynamodb.AttributeValue) error) error {
+	for _, dataFile := range e.DataFiles {
+		err := e.readDataFile(dataFile, fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Export) readDataFile(dataFile string, fn func(item map[string]*dynamodb.AttributeValue) error) error {
+	f, err := os.Open(filepath.Clean(dataFile))
+	if err != nil {
+		return errors.Wrapf(err, "export: cannot open data file '%s'", dataFile)
+	}
+	defer f.Close()
+
+	source := Source{Path: dataFile, Name: definitionName(filepath.Base(dataFile))}
+	return readExportItems(f, source, fn)
+}
+
+// ExportFixturesDecoder decodes data files of DynamoDB table export, gzipped or not, as fixtures of a single table.
+// Use it with a loader reading data files, e.g. NewFilesystemDirectoryLoader("export/data", "gz"),
+// or DynamoTester.LoadExport for exports that don't fit in memory.
+type ExportFixturesDecoder struct {
+	// TableName is the table items are written into, as data files don't contain it
+	TableName string
+}
+
+// NewExportFixturesDecoder creates ExportFixturesDecoder writing items into given table
+func NewExportFixturesDecoder(tableName string) *ExportFixturesDecoder {
+	return &ExportFixturesDecoder{TableName: tableName}
+}
+
+func (d *ExportFixturesDecoder) Decode(input []Definition) (TableWriteRequests, error) {
+	writeRequests := make(TableWriteRequests)
+	for _, dataFile := range input {
+		err := readExportItems(bytes.NewReader(dataFile.Contents), dataFile.Source, func(item map[string]*dynamodb.AttributeValue) error {
+			writeRequest := &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}}
+			writeRequests[d.TableName] = append(writeRequests[d.TableName], writeRequest)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return writeRequests, nil
+}
+
+// readExportItems reads JSON lines of {"Item": {...}} objects, decompressing them first if they're gzipped
+func readExportItems(r io.Reader, source Source, fn func(item map[string]*dynamodb.AttributeValue) error) error {
+	buffered := bufio.NewReader(r)
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return &DecodeError{Source: source, Err: errors.Wrap(err, "cannot decompress data file")}
+		}
+		defer gzipReader.Close()
+		r = gzipReader
+	} else {
+		r = buffered
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxExportLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var exported struct {
+			Item map[string]json.RawMessage
+		}
+		err := json.Unmarshal(scanner.Bytes(), &exported)
+		if err != nil {
+			return &DecodeError{Source: source, Line: line, Err: errors.Wrap(err, "cannot parse item")}
+		}
+		if len(exported.Item) == 0 {
+			return &DecodeError{Source: source, Line: line, Err: errors.New("line has no Item")}
+		}
+		item, err := unmarshalTypedAttributes(exported.Item)
+		if err != nil {
+			return &DecodeError{Source: source, Line: line, Err: errors.Wrap(err, "cannot parse item")}
+		}
+
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &DecodeError{Source: source, Line: line + 1, Err: errors.Wrap(err, "cannot read data file")}
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package dynamotest_test
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenExport(t *testing.T) {
+	dir := t.TempDir()
+	writeSampleExport(t, dir, 2, 3)
+
+	export, err := dynamotest.OpenExport(dir)
+
+	require.NoError(t, err)
+	require.Equal(t, &dynamotest.Export{
+		Dir:       dir,
+		TableName: "tableName",
+		ItemCount: 6,
+		DataFiles: []string{
+			filepath.Join(dir, "data", "file0.json.gz"),
+			filepath.Join(dir, "data", "file1.json.gz"),
+		},
+	}, export)
+}
+
+func TestOpenExportWithUnsupportedFormat(t *testing.T) {
+	dir := t.TempDir()
+	writeSampleExport(t, dir, 1, 1)
+	summary := `{"tableArn": "arn:aws:dynamodb:eu-west-1:123456789012:table/tableName", "outputFormat": "ION"}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "manifest-summary.json"), []byte(summary), 0600))
+
+	_, err := dynamotest.OpenExport(dir)
+
+	require.Error(t, err)
+}
+
+func TestExportReadItems(t *testing.T) {
+	dir := t.TempDir()
+	writeSampleExport(t, dir, 2, 2)
+	export, err := dynamotest.OpenExport(dir)
+	require.NoError(t, err)
+
+	var ids []string
+	err = export.ReadItems(func(item map[string]*dynamodb.AttributeValue) error {
+		ids = append(ids, aws.StringValue(item["ID"].N))
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"0", "1", "2", "3"}, ids)
+}
+
+func TestExportFixturesDecoder(t *testing.T) {
+	decoder := dynamotest.NewExportFixturesDecoder("tableName")
+	plain := `{"Item":{"ID":{"N":"1"},"Tags":{"SS":["a"]}}}` + "\n\n" + `{"Item":{"ID":{"N":"12345678901234567890"}}}` + "\n"
+	input := []dynamotest.Definition{
+		definition("export/data/plain.json", "plain", plain),
+		definition("export/data/gzipped.json.gz", "gzipped", string(gzipBytes(t, `{"Item":{"ID":{"N":"3"}}}`+"\n"))),
+	}
+	expected := dynamotest.TableWriteRequests{
+		"tableName": {
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID":   {N: aws.String("1")},
+				"Tags": {SS: aws.StringSlice([]string{"a"})},
+			}}},
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID": {N: aws.String("12345678901234567890")},
+			}}},
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID": {N: aws.String("3")},
+			}}},
+		},
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestExportFixturesDecoderPointsAtInvalidLine(t *testing.T) {
+	decoder := dynamotest.NewExportFixturesDecoder("tableName")
+	input := []dynamotest.Definition{
+		definition("export/data/file.json", "file", `{"Item":{"ID":{"N":"1"}}}`+"\n"+`{"Item":{"ID":{"X":"2"}}}`+"\n"),
+	}
+
+	_, err := decoder.Decode(input)
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, 2, decodeErr.Line)
+	require.Contains(t, err.Error(), "export/data/file.json:2: cannot parse item: attribute 'ID'")
+}
+
+func TestDynamoTesterLoadExport(t *testing.T) {
+	dir := t.TempDir()
+	writeSampleExport(t, dir, 3, 900)
+	export, err := dynamotest.OpenExport(dir)
+	require.NoError(t, err)
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+
+	err = tester.LoadExport(export)
+
+	require.NoError(t, err)
+	require.Len(t, dynamoSvc.items(tester.TableNameFor("tableName")), 2700)
+}
+
+func TestDynamoTesterLoadExportWithoutTableName(t *testing.T) {
+	dir := t.TempDir()
+	writeSampleExport(t, dir, 1, 10)
+	export, err := dynamotest.OpenExport(dir)
+	require.NoError(t, err)
+	export.TableName = ""
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+
+	err = tester.LoadExport(export)
+
+	require.EqualError(t, err, fmt.Sprintf("fixtures: export of '%s' has no table name", dir))
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func TestDynamoTesterLoadExportReportsWriteErrorOnce(t *testing.T) {
+	dir := t.TempDir()
+	writeSampleExport(t, dir, 1, 10)
+	export, err := dynamotest.OpenExport(dir)
+	require.NoError(t, err)
+	dynamoSvc := newFakeDynamoDB()
+	dynamoSvc.batchWriteErr = errors.New("throttled")
+	tester := createSampleDynamoTester(dynamoSvc)
+
+	err = tester.LoadExport(export)
+
+	require.EqualError(t, err, "fixtures: cannot write items: throttled")
+}
+
+// writeSampleExport writes an export of "tableName" table with given number of gzipped data files and items in each
+func writeSampleExport(t *testing.T, dir string, files, itemsPerFile int) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "data"), 0700))
+
+	summary := fmt.Sprintf(`{
+		"version": "2020-06-30",
+		"exportArn": "arn:aws:dynamodb:eu-west-1:123456789012:table/tableName/export/01234567890123-abcdefgh",
+		"tableArn": "arn:aws:dynamodb:eu-west-1:123456789012:table/tableName",
+		"itemCount": %d,
+		"outputFormat": "DYNAMODB_JSON",
+		"manifestFilesS3Key": "prefix/AWSDynamoDB/01234567890123-abcdefgh/manifest-files.json"
+	}`, files*itemsPerFile)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "manifest-summary.json"), []byte(summary), 0600))
+
+	var manifestFiles strings.Builder
+	for f := 0; f < files; f++ {
+		var lines strings.Builder
+		for i := 0; i < itemsPerFile; i++ {
+			id := f*itemsPerFile + i
+			fmt.Fprintf(&lines, `{"Item":{"ID":{"N":"%d"},"Name":{"S":"item %d"}}}`+"\n", id, id)
+		}
+		dataFile := fmt.Sprintf("file%d.json.gz", f)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "data", dataFile), gzipBytes(t, lines.String()), 0600))
+		fmt.Fprintf(&manifestFiles, `{"itemCount":%d,"dataFileS3Key":"prefix/AWSDynamoDB/01234567890123-abcdefgh/data/%s"}`+"\n", itemsPerFile, dataFile)
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "manifest-files.json"), []byte(manifestFiles.String()), 0600))
+}
+
+func gzipBytes(t *testing.T, contents string) []byte {
+	t.Helper()
+	var buf strings.Builder
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return []byte(buf.String())
+}
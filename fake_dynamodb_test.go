@@ -0,0 +1,482 @@
+package dynamotest_test
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamoDB is an in-memory implementation of the parts of DynamoDB API used by dynamotest
+type fakeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+
+	mutex  sync.Mutex
+	tables map[string]*fakeTable
+
+	// rejectWrites is the number of next write requests returned as unprocessed by BatchWriteItem
+	rejectWrites int
+	// batchWriteErr is returned by BatchWriteItem when set
+	batchWriteErr   error
+	batchWriteCalls []*dynamodb.BatchWriteItemInput
+	updateItemCalls []*dynamodb.UpdateItemInput
+
+	// creatingDescribes and deletingDescribes are the numbers of DescribeTable calls
+	// reporting a table as CREATING or DELETING after CreateTable or DeleteTable call
+	creatingDescribes int
+	deletingDescribes int
+
+	// deleteTableErrors are returned by DeleteTable for given table names
+	deleteTableErrors map[string]error
+
+	// scanPageSize limits the number of items returned by a single Scan call
+	scanPageSize int
+	scanCalls    int
+}
+
+type fakeTable struct {
+	description      *dynamodb.TableDescription
+	timeToLive       *dynamodb.TimeToLiveDescription
+	tags             []*dynamodb.Tag
+	items            map[string]map[string]*dynamodb.AttributeValue
+	pendingDescribes int
+	deleting         bool
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{tables: make(map[string]*fakeTable)}
+}
+
+func (f *fakeDynamoDB) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	tableName := aws.StringValue(input.TableName)
+	if _, ok := f.tables[tableName]; ok {
+		return nil, awserr.New(dynamodb.ErrCodeResourceInUseException, "table already exists: "+tableName, nil)
+	}
+
+	description := &dynamodb.TableDescription{
+		TableName:            input.TableName,
+		TableArn:             aws.String("arn:aws:dynamodb:eu-west-1:123456789012:table/" + tableName),
+		TableStatus:          aws.String(dynamodb.TableStatusActive),
+		CreationDateTime:     aws.Time(time.Now()),
+		AttributeDefinitions: input.AttributeDefinitions,
+		KeySchema:            input.KeySchema,
+		StreamSpecification:  input.StreamSpecification,
+	}
+	if input.BillingMode != nil {
+		description.BillingModeSummary = &dynamodb.BillingModeSummary{BillingMode: input.BillingMode}
+	}
+	if input.SSESpecification != nil && aws.BoolValue(input.SSESpecification.Enabled) {
+		description.SSEDescription = &dynamodb.SSEDescription{
+			Status:          aws.String(dynamodb.SSEStatusEnabled),
+			SSEType:         input.SSESpecification.SSEType,
+			KMSMasterKeyArn: input.SSESpecification.KMSMasterKeyId,
+		}
+	}
+	if input.ProvisionedThroughput != nil {
+		description.ProvisionedThroughput = &dynamodb.ProvisionedThroughputDescription{
+			ReadCapacityUnits:  input.ProvisionedThroughput.ReadCapacityUnits,
+			WriteCapacityUnits: input.ProvisionedThroughput.WriteCapacityUnits,
+		}
+	}
+	for _, l := range input.LocalSecondaryIndexes {
+		description.LocalSecondaryIndexes = append(description.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndexDescription{
+			IndexName:  l.IndexName,
+			KeySchema:  l.KeySchema,
+			Projection: l.Projection,
+		})
+	}
+	for _, g := range input.GlobalSecondaryIndexes {
+		gsi := &dynamodb.GlobalSecondaryIndexDescription{
+			IndexName:   g.IndexName,
+			KeySchema:   g.KeySchema,
+			Projection:  g.Projection,
+			IndexStatus: aws.String(dynamodb.IndexStatusActive),
+		}
+		if g.ProvisionedThroughput != nil {
+			gsi.ProvisionedThroughput = &dynamodb.ProvisionedThroughputDescription{
+				ReadCapacityUnits:  g.ProvisionedThroughput.ReadCapacityUnits,
+				WriteCapacityUnits: g.ProvisionedThroughput.WriteCapacityUnits,
+			}
+		}
+		description.GlobalSecondaryIndexes = append(description.GlobalSecondaryIndexes, gsi)
+	}
+
+	f.tables[tableName] = &fakeTable{
+		description:      description,
+		items:            make(map[string]map[string]*dynamodb.AttributeValue),
+		pendingDescribes: f.creatingDescribes,
+		tags:             input.Tags,
+		timeToLive: &dynamodb.TimeToLiveDescription{
+			TimeToLiveStatus: aws.String(dynamodb.TimeToLiveStatusDisabled),
+		},
+	}
+
+	return &dynamodb.CreateTableOutput{TableDescription: description}, nil
+}
+
+func (f *fakeDynamoDB) DeleteTable(input *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	tableName := aws.StringValue(input.TableName)
+	if err, ok := f.deleteTableErrors[tableName]; ok {
+		return nil, err
+	}
+
+	table, ok := f.tables[tableName]
+	if !ok || table.deleting {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found: "+tableName, nil)
+	}
+
+	if f.deletingDescribes > 0 {
+		table.deleting = true
+		table.pendingDescribes = f.deletingDescribes
+	} else {
+		delete(f.tables, tableName)
+	}
+
+	return &dynamodb.DeleteTableOutput{TableDescription: table.description}, nil
+}
+
+func (f *fakeDynamoDB) DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	tableName := aws.StringValue(input.TableName)
+	table, ok := f.tables[tableName]
+	if !ok {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found: "+tableName, nil)
+	}
+
+	if table.pendingDescribes == 0 {
+		if table.deleting {
+			delete(f.tables, tableName)
+			return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found: "+tableName, nil)
+		}
+
+		return &dynamodb.DescribeTableOutput{Table: table.description}, nil
+	}
+
+	table.pendingDescribes--
+	description := *table.description
+	description.TableStatus = aws.String(dynamodb.TableStatusCreating)
+	if table.deleting {
+		description.TableStatus = aws.String(dynamodb.TableStatusDeleting)
+	}
+
+	return &dynamodb.DescribeTableOutput{Table: &description}, nil
+}
+
+func (f *fakeDynamoDB) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.batchWriteCalls = append(f.batchWriteCalls, input)
+	if f.batchWriteErr != nil {
+		return nil, f.batchWriteErr
+	}
+
+	requestsCount := 0
+	for _, requests := range input.RequestItems {
+		requestsCount += len(requests)
+	}
+	if requestsCount > 25 {
+		return nil, awserr.New("ValidationException", "too many items requested for the BatchWriteItem call", nil)
+	}
+
+	unprocessed := make(map[string][]*dynamodb.WriteRequest)
+	for tableName, requests := range input.RequestItems {
+		table, ok := f.tables[tableName]
+		if !ok || !table.isActive() {
+			return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found: "+tableName, nil)
+		}
+		keys := make(map[string]bool)
+		for _, r := range requests {
+			var attributes map[string]*dynamodb.AttributeValue
+			if r.PutRequest != nil {
+				attributes = r.PutRequest.Item
+			}
+			if r.DeleteRequest != nil {
+				attributes = r.DeleteRequest.Key
+			}
+			if keys[table.key(attributes)] {
+				return nil, awserr.New("ValidationException", "Provided list of item keys contains duplicates", nil)
+			}
+			keys[table.key(attributes)] = true
+		}
+
+		for _, r := range requests {
+			if f.rejectWrites > 0 {
+				f.rejectWrites--
+				unprocessed[tableName] = append(unprocessed[tableName], r)
+				continue
+			}
+
+			if r.PutRequest != nil {
+				table.items[table.key(r.PutRequest.Item)] = r.PutRequest.Item
+			}
+			if r.DeleteRequest != nil {
+				delete(table.items, table.key(r.DeleteRequest.Key))
+			}
+		}
+	}
+
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: unprocessed}, nil
+}
+
+// UpdateItem supports SET and REMOVE clauses of placeholders only, e.g. "SET #a = :a REMOVE #b"
+func (f *fakeDynamoDB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.updateItemCalls = append(f.updateItemCalls, input)
+	tableName := aws.StringValue(input.TableName)
+	table, ok := f.tables[tableName]
+	if !ok || !table.isActive() {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found: "+tableName, nil)
+	}
+
+	item := make(map[string]*dynamodb.AttributeValue)
+	for name, value := range table.items[table.key(input.Key)] {
+		item[name] = value
+	}
+	for name, value := range input.Key {
+		item[name] = value
+	}
+
+	clause := ""
+	for _, token := range strings.Fields(strings.NewReplacer(",", " ", "=", " ").Replace(aws.StringValue(input.UpdateExpression))) {
+		switch {
+		case token == "SET" || token == "REMOVE":
+			clause = token
+		case strings.HasPrefix(token, "#") && clause == "REMOVE":
+			delete(item, aws.StringValue(input.ExpressionAttributeNames[token]))
+		case strings.HasPrefix(token, "#") && clause == "SET":
+			item[aws.StringValue(input.ExpressionAttributeNames[token])] = input.ExpressionAttributeValues[":"+token[1:]]
+		}
+	}
+	table.items[table.key(input.Key)] = item
+
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.scanCalls++
+	tableName := aws.StringValue(input.TableName)
+	table, ok := f.tables[tableName]
+	if !ok || !table.isActive() {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found: "+tableName, nil)
+	}
+
+	var projected []string
+	for _, p := range strings.Split(aws.StringValue(input.ProjectionExpression), ",") {
+		p = strings.TrimSpace(p)
+		if name, ok := input.ExpressionAttributeNames[p]; ok {
+			p = aws.StringValue(name)
+		}
+		if p != "" {
+			projected = append(projected, p)
+		}
+	}
+
+	keys := make([]string, 0, len(table.items))
+	for k := range table.items {
+		if input.TotalSegments != nil && fakeSegment(k, *input.TotalSegments) != aws.Int64Value(input.Segment) {
+			continue
+		}
+		if input.ExclusiveStartKey != nil && k <= table.key(input.ExclusiveStartKey) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	output := &dynamodb.ScanOutput{}
+	for i, k := range keys {
+		if f.scanPageSize > 0 && i == f.scanPageSize {
+			output.LastEvaluatedKey = table.keyOf(output.Items[len(output.Items)-1])
+			break
+		}
+
+		item := table.items[k]
+		if len(projected) > 0 {
+			item = make(map[string]*dynamodb.AttributeValue)
+			for _, p := range projected {
+				item[p] = table.items[k][p]
+			}
+		}
+		output.Items = append(output.Items, item)
+	}
+
+	return output, nil
+}
+
+func (f *fakeDynamoDB) DescribeTimeToLive(
+	input *dynamodb.DescribeTimeToLiveInput,
+) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	tableName := aws.StringValue(input.TableName)
+	table, ok := f.tables[tableName]
+	if !ok {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found: "+tableName, nil)
+	}
+
+	return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: table.timeToLive}, nil
+}
+
+func (f *fakeDynamoDB) UpdateTimeToLive(input *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	tableName := aws.StringValue(input.TableName)
+	table, ok := f.tables[tableName]
+	if !ok || !table.isActive() {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "table not found: "+tableName, nil)
+	}
+
+	status := dynamodb.TimeToLiveStatusDisabled
+	if aws.BoolValue(input.TimeToLiveSpecification.Enabled) {
+		status = dynamodb.TimeToLiveStatusEnabled
+	}
+	table.timeToLive = &dynamodb.TimeToLiveDescription{
+		AttributeName:    input.TimeToLiveSpecification.AttributeName,
+		TimeToLiveStatus: aws.String(status),
+	}
+
+	return &dynamodb.UpdateTimeToLiveOutput{TimeToLiveSpecification: input.TimeToLiveSpecification}, nil
+}
+
+// ListTagsOfResource returns tags one by one to exercise pagination
+func (f *fakeDynamoDB) ListTagsOfResource(
+	input *dynamodb.ListTagsOfResourceInput,
+) (*dynamodb.ListTagsOfResourceOutput, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, table := range f.tables {
+		if aws.StringValue(table.description.TableArn) != aws.StringValue(input.ResourceArn) {
+			continue
+		}
+
+		var offset int
+		if input.NextToken != nil {
+			offset, _ = strconv.Atoi(*input.NextToken)
+		}
+		output := &dynamodb.ListTagsOfResourceOutput{}
+		if offset < len(table.tags) {
+			output.Tags = table.tags[offset : offset+1]
+		}
+		if offset+1 < len(table.tags) {
+			output.NextToken = aws.String(strconv.Itoa(offset + 1))
+		}
+
+		return output, nil
+	}
+
+	return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "resource not found", nil)
+}
+
+// ListTables returns at most two table names per call to exercise pagination
+func (f *fakeDynamoDB) ListTables(input *dynamodb.ListTablesInput) (*dynamodb.ListTablesOutput, error) {
+	output := &dynamodb.ListTablesOutput{}
+	for _, tableName := range f.tableNames() {
+		if input.ExclusiveStartTableName != nil && tableName <= *input.ExclusiveStartTableName {
+			continue
+		}
+		if len(output.TableNames) == 2 {
+			output.LastEvaluatedTableName = output.TableNames[1]
+			break
+		}
+		output.TableNames = append(output.TableNames, aws.String(tableName))
+	}
+
+	return output, nil
+}
+
+func fakeSegment(key string, totalSegments int64) int64 {
+	var sum int64
+	for _, c := range key {
+		sum += int64(c)
+	}
+
+	return sum % totalSegments
+}
+
+// items returns all items stored in given table, sorted by primary key
+func (f *fakeDynamoDB) items(tableName string) []map[string]*dynamodb.AttributeValue {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	table, ok := f.tables[tableName]
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(table.items))
+	for k := range table.items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]map[string]*dynamodb.AttributeValue, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, table.items[k])
+	}
+
+	return result
+}
+
+func (f *fakeDynamoDB) tableNames() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	result := make([]string, 0, len(f.tables))
+	for tableName := range f.tables {
+		result = append(result, tableName)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func (t *fakeTable) isActive() bool {
+	return t.pendingDescribes == 0 && !t.deleting
+}
+
+func (t *fakeTable) keyOf(item map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	key := make(map[string]*dynamodb.AttributeValue)
+	for _, k := range t.description.KeySchema {
+		key[aws.StringValue(k.AttributeName)] = item[aws.StringValue(k.AttributeName)]
+	}
+
+	return key
+}
+
+func (t *fakeTable) key(item map[string]*dynamodb.AttributeValue) string {
+	var parts []string
+	for _, k := range t.description.KeySchema {
+		v := item[aws.StringValue(k.AttributeName)]
+		if v == nil {
+			parts = append(parts, "")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s|%s|%x", aws.StringValue(v.S), aws.StringValue(v.N), v.B))
+	}
+
+	return strings.Join(parts, "#")
+}
@@ -0,0 +1,128 @@
+package dynamotest
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FSDirectoryLoader reads files from given directory of fs.FS, e.g. embed.FS or an archive opened with OpenArchiveFS.
+// It resolves names, walks subdirectories and filters files the same way as FilesystemDirectoryLoader.
+type FSDirectoryLoader struct {
+	fsys       fs.FS
+	dir        string
+	extensions []string
+	// Include limits files read from the whole directory to the ones matching any of the patterns,
+	// see FilesystemDirectoryLoader
+	Include []string
+	// Exclude skips files read from the whole directory matching any of the patterns
+	Exclude []string
+}
+
+// NewFSDirectoryLoader creates new instance of FSDirectoryLoader reading files with any of given extensions.
+// The directory is a slash-separated path within fsys, "." stands for its root.
+func NewFSDirectoryLoader(fsys fs.FS, dir string, extensions ...string) *FSDirectoryLoader {
+	return &FSDirectoryLoader{fsys: fsys, dir: path.Clean(dir), extensions: extensions}
+}
+
+// NewArchiveLoader creates FSDirectoryLoader reading files from given directory of a zip or tar archive.
+// Archive format is recognized by its extension: ".zip", ".tar", ".tar.gz" or ".tgz".
+func NewArchiveLoader(archivePath string, dir string, extensions ...string) (*FSDirectoryLoader, error) {
+	fsys, err := OpenArchiveFS(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFSDirectoryLoader(fsys, dir, extensions...), nil
+}
+
+func (r *FSDirectoryLoader) ReadDefinitions(names ...string) ([]Definition, error) {
+	var files []Source
+	if len(names) == 0 {
+		var err error
+		files, err = listFilesInFS(r.fsys, r.dir, r.extensions, r.Include, r.Exclude)
+		if err != nil {
+			return nil, errors.Wrap(err, "migrate: cannot read definitions")
+		}
+	} else {
+		for _, n := range names {
+			filePath := resolveNamedFile(n, r.extensions, func(fileName string) (string, bool) {
+				filePath := path.Join(r.dir, fileName)
+				_, err := fs.Stat(r.fsys, filePath)
+				return filePath, err == nil
+			})
+			files = append(files, Source{Path: filePath, Name: n})
+		}
+	}
+
+	var result []Definition
+	for _, file := range files {
+		contents, err := fs.ReadFile(r.fsys, file.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrate: cannot read file: '%s'", file.Path)
+		}
+		result = append(result, Definition{Source: file, Contents: contents})
+	}
+
+	return result, nil
+}
+
+func listFilesInFS(fsys fs.FS, directory string, extensions, include, exclude []string) ([]Source, error) {
+	relativePaths, err := listMatchingFiles(fsys, directory, extensions, include, exclude)
+	if err != nil {
+		return nil, errors.Wrapf(err, "migrate: cannot load files from migrations path: '%s'", directory)
+	}
+
+	files := make([]Source, 0, len(relativePaths))
+	for _, relativePath := range relativePaths {
+		files = append(files, Source{Path: path.Join(directory, relativePath), Name: definitionName(relativePath)})
+	}
+
+	return files, nil
+}
+
+// OpenArchiveFS reads a zip or tar archive into memory and exposes it as fs.FS.
+// Archive format is recognized by its extension: ".zip", ".tar", ".tar.gz" or ".tgz".
+func OpenArchiveFS(archivePath string) (fs.FS, error) {
+	contents, err := ioutil.ReadFile(filepath.Clean(archivePath))
+	if err != nil {
+		return nil, errors.Wrapf(err, "migrate: cannot read archive: '%s'", archivePath)
+	}
+
+	lowerPath := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lowerPath, ".zip"):
+		fsys, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrate: cannot open zip archive: '%s'", archivePath)
+		}
+		return fsys, nil
+	case strings.HasSuffix(lowerPath, ".tar.gz"), strings.HasSuffix(lowerPath, ".tgz"):
+		gzipReader, err := gzip.NewReader(bytes.NewReader(contents))
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrate: cannot open gzip archive: '%s'", archivePath)
+		}
+		return openTarFS(gzipReader, archivePath)
+	case strings.HasSuffix(lowerPath, ".tar"):
+		return openTarFS(bytes.NewReader(contents), archivePath)
+	default:
+		return nil, errors.Errorf("migrate: unsupported archive format: '%s'", archivePath)
+	}
+}
+
+func openTarFS(r io.Reader, archivePath string) (fs.FS, error) {
+	fsys, err := readTarFS(r, archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return fsys, nil
+}
@@ -0,0 +1,165 @@
+package dynamotest_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"embed"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+//go:embed test_resources
+var testResources embed.FS
+
+func TestFSDirectoryLoader(t *testing.T) {
+	loader := dynamotest.NewFSDirectoryLoader(testResources, "test_resources", "json")
+	loader.Exclude = []string{"a.json"}
+	expectedResult := []dynamotest.Definition{
+		definition("test_resources/b.json", "b", `{
+  "Name": "This is a Test B file"
+}
+`),
+		definition("test_resources/nested/c.json", "nested/c", `{
+  "Name": "This is a Test nested/C file"
+}
+`),
+	}
+	actualResult, err := loader.ReadDefinitions()
+	require.NoError(t, err)
+	require.Equal(t, expectedResult, actualResult)
+}
+
+func TestFSDirectoryLoaderWithNames(t *testing.T) {
+	loader := dynamotest.NewFSDirectoryLoader(createSampleMapFS(), ".", "json")
+	actualResult, err := loader.ReadDefinitions("users/b", "a")
+	require.NoError(t, err)
+	require.Equal(t, []dynamotest.Definition{
+		definition("users/b.json", "users/b", "B"),
+		definition("a.json", "a", "A"),
+	}, actualResult)
+}
+
+func TestFSDirectoryLoaderWithMultipleExtensions(t *testing.T) {
+	loader := dynamotest.NewFSDirectoryLoader(createSampleMapFS(), "users", "json", "yml")
+	actualResult, err := loader.ReadDefinitions()
+	require.NoError(t, err)
+	require.Equal(t, []dynamotest.Definition{
+		definition("users/b.json", "b", "B"),
+		definition("users/d.yml", "d", "D"),
+		definition("users/premium/c.json", "premium/c", "C"),
+	}, actualResult)
+
+	actualResult, err = loader.ReadDefinitions("d")
+	require.NoError(t, err)
+	require.Equal(t, []dynamotest.Definition{definition("users/d.yml", "d", "D")}, actualResult)
+}
+
+func TestFSDirectoryLoaderWithMissingName(t *testing.T) {
+	loader := dynamotest.NewFSDirectoryLoader(createSampleMapFS(), ".", "json")
+	_, err := loader.ReadDefinitions("missing")
+	require.Error(t, err)
+}
+
+func TestArchiveLoader(t *testing.T) {
+	archives := map[string]func(path string, fsys fstest.MapFS) error{
+		"fixtures.zip":    writeZipArchive,
+		"fixtures.tar":    writeTarArchive,
+		"fixtures.tar.gz": writeTarArchive,
+	}
+
+	for archiveName, write := range archives {
+		t.Run(archiveName, func(t *testing.T) {
+			archivePath := filepath.Join(t.TempDir(), archiveName)
+			require.NoError(t, write(archivePath, createSampleMapFS()))
+
+			loader, err := dynamotest.NewArchiveLoader(archivePath, "users", "json")
+			require.NoError(t, err)
+
+			actualResult, err := loader.ReadDefinitions()
+			require.NoError(t, err)
+			require.Equal(t, []dynamotest.Definition{
+				definition("users/b.json", "b", "B"),
+				definition("users/premium/c.json", "premium/c", "C"),
+			}, actualResult)
+		})
+	}
+}
+
+func TestOpenArchiveFSOfTarArchive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "fixtures.tar")
+	require.NoError(t, writeTarArchive(archivePath, createSampleMapFS()))
+
+	fsys, err := dynamotest.OpenArchiveFS(archivePath)
+	require.NoError(t, err)
+	require.NoError(t, fstest.TestFS(fsys, "a.json", "users/b.json", "users/premium/c.json", "users/d.yml"))
+}
+
+func TestArchiveLoaderWithUnsupportedFormat(t *testing.T) {
+	_, err := dynamotest.NewArchiveLoader("test_resources/a.json", ".", "json")
+	require.Error(t, err)
+}
+
+func createSampleMapFS() fstest.MapFS {
+	return fstest.MapFS{
+		"a.json":               {Data: []byte("A")},
+		"users/b.json":         {Data: []byte("B")},
+		"users/premium/c.json": {Data: []byte("C")},
+		"users/d.yml":          {Data: []byte("D")},
+	}
+}
+
+func writeZipArchive(path string, fsys fstest.MapFS) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, file := range fsys {
+		fw, err := w.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(file.Data); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+func writeTarArchive(path string, fsys fstest.MapFS) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var out io.Writer = f
+	if filepath.Ext(path) == ".gz" {
+		gw := gzip.NewWriter(f)
+		defer gw.Close()
+		out = gw
+	}
+
+	w := tar.NewWriter(out)
+	for name, file := range fsys {
+		err = w.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(file.Data))})
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(file.Data); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
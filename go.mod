@@ -1,10 +1,11 @@
 module github.com/eps90/dynamotest
 
-go 1.12
+go 1.16
 
 require (
 	github.com/aws/aws-sdk-go v1.23.13
-	github.com/pkg/errors v0.8.1
+	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.4.0
 	golang.org/x/net v0.0.0-20190827160401-ba9fcec4b297 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
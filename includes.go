@@ -0,0 +1,413 @@
+package dynamotest
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// fixtureHeader lists fixtures a fixture depends on:
+// items of included fixtures are loaded along with its own items, while items of the extended fixture
+// are patched with its own items of the same primary key, and the rest of its items is added to them.
+type fixtureHeader struct {
+	Include []string `yaml:"include"`
+	Extends string   `yaml:"extends"`
+}
+
+// headerKeyPattern finds include or extends keys starting a line of contents that cannot be parsed
+var headerKeyPattern = regexp.MustCompile(`(?m)^\s*\{?\s*"?(include|extends)"?\s*:`)
+
+// readFixtureHeader reads the header of JSON or YAML fixture. Contents that cannot be parsed, e.g. data files
+// of exports, are left to the decoder, unless they seem to declare include or extends.
+func readFixtureHeader(input Definition) (fixtureHeader, error) {
+	var header fixtureHeader
+	var document yaml.Node
+	if yaml.Unmarshal(input.Contents, &document) != nil {
+		if match := headerKeyPattern.FindSubmatch(input.Contents); match != nil {
+			return header, &DecodeError{Source: input.Source, Err: errors.Errorf("cannot read %s of unparsable fixture", match[1])}
+		}
+		return header, nil
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return header, nil
+	}
+
+	root := document.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		var err error
+		switch root.Content[i].Value {
+		case "include":
+			err = root.Content[i+1].Decode(&header.Include)
+		case "extends":
+			err = root.Content[i+1].Decode(&header.Extends)
+		}
+		if err != nil {
+			return header, &DecodeError{Source: input.Source, Line: root.Content[i+1].Line, Column: root.Content[i+1].Column,
+				Err: errors.Wrapf(err, "invalid %s", root.Content[i].Value)}
+		}
+	}
+
+	return header, nil
+}
+
+// LoadOption configures a single LoadFixturesWith call
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	overrides []fixtureOverride
+}
+
+type fixtureOverride struct {
+	tableName string
+	key       map[string]interface{}
+	patch     map[string]interface{}
+}
+
+// WithOverride patches the item of given table and primary key, after fixtures are decoded.
+// Attributes of the patch are marshaled with dynamodbattribute, nil values remove attributes.
+func WithOverride(tableName string, key map[string]interface{}, patch map[string]interface{}) LoadOption {
+	return func(o *loadOptions) {
+		o.overrides = append(o.overrides, fixtureOverride{tableName: tableName, key: key, patch: patch})
+	}
+}
+
+// fixturesExpander decodes fixtures together with fixtures they include or extend
+type fixturesExpander struct {
+	tester *DynamoTester
+	// loaded are paths of decoded fixtures, every fixture is included only once
+	loaded map[string]bool
+	// expanding are paths of fixtures being decoded, detects circular extends
+	expanding map[string]bool
+	// origins are fixtures and positions operations have been decoded from
+	origins map[*Operation]itemOrigin
+	// pending are operations of expanded fixtures of given names, by path, until another fixture extends them
+	pending map[string]TableOperations
+	// extendedBy are paths of fixtures extending other fixtures, by path of the extended fixture
+	extendedBy map[string]string
+	// extensions are patches of extended fixtures, applied once references are resolved, innermost first
+	extensions []fixtureExtension
+}
+
+// fixtureExtension holds operations of the extended fixture and puts of the extending one patching them
+type fixtureExtension struct {
+	path    string
+	extends string
+	base    TableOperations
+	patches TableOperations
+}
+
+// itemOrigin points at the item a request has been decoded from
+type itemOrigin struct {
+	Source
+	// Index is the position of the item among items of the same table in the fixture
+	Index int
+}
+
+// decodeFixtures reads and decodes fixtures of given names, or all of them, with their includes and extended fixtures.
+// References are resolved before items of extended fixtures are patched, so keys can be given with references.
+// It returns origins of all decoded operations as well.
+func (t *DynamoTester) decodeFixtures(names []string) (TableOperations, map[*Operation]itemOrigin, error) {
+	definitions, err := t.FixturesLoader.ReadDefinitions(names...)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "fixtures: cannot load fixture files")
+	}
+
+	e := &fixturesExpander{
+		tester:     t,
+		loaded:     make(map[string]bool),
+		expanding:  make(map[string]bool),
+		origins:    make(map[*Operation]itemOrigin),
+		pending:    make(map[string]TableOperations),
+		extendedBy: make(map[string]string),
+	}
+	paths := make([]string, 0, len(definitions))
+	for _, fixtureDefinition := range definitions {
+		if e.loaded[fixtureDefinition.Path] {
+			continue
+		}
+		expanded, err := e.expand(fixtureDefinition)
+		if err != nil {
+			return nil, nil, err
+		}
+		e.pending[fixtureDefinition.Path] = expanded
+		paths = append(paths, fixtureDefinition.Path)
+	}
+
+	// fixtures extended by fixtures loaded after them are no longer pending, their items are written by the extending one
+	operations := make(TableOperations)
+	for _, fixturePath := range paths {
+		operations.merge(e.pending[fixturePath])
+	}
+
+	err = resolveReferences(operations, e.origins)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patched := make(map[*Operation]bool)
+	for _, extension := range e.extensions {
+		err = t.patchItems(extension.base, extension.patches, e.origins, patched)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "fixtures: cannot extend '%s' in '%s'", extension.extends, extension.path)
+		}
+	}
+	for tableName, tableOperations := range operations {
+		remaining := tableOperations[:0]
+		for _, operation := range tableOperations {
+			if !patched[operation] {
+				remaining = append(remaining, operation)
+			}
+		}
+		operations[tableName] = remaining
+	}
+
+	return operations, e.origins, nil
+}
+
+func (e *fixturesExpander) expand(input Definition) (TableOperations, error) {
+	if e.expanding[input.Path] {
+		return nil, errors.Errorf("fixtures: '%s' extends itself", input.Path)
+	}
+	e.expanding[input.Path] = true
+	defer delete(e.expanding, input.Path)
+	e.loaded[input.Path] = true
+
+	input, decoder, err := e.render(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "fixtures: cannot parse fixture files")
+	}
+	header, err := readFixtureHeader(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "fixtures: cannot parse fixture files")
+	}
+
+	operations := make(TableOperations)
+	for _, name := range header.Include {
+		included, err := e.read(input, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, includedDefinition := range included {
+			if e.loaded[includedDefinition.Path] {
+				continue
+			}
+			expanded, err := e.expand(includedDefinition)
+			if err != nil {
+				return nil, err
+			}
+			operations.merge(expanded)
+		}
+	}
+
+	own, err := decodeOperations(decoder, []Definition{input})
+	if err != nil {
+		return nil, errors.Wrap(err, "fixtures: cannot parse fixture files")
+	}
+	for _, tableOperations := range own {
+		for i, operation := range tableOperations {
+			e.origins[operation] = itemOrigin{Source: input.Source, Index: i}
+		}
+	}
+
+	if header.Extends != "" {
+		extended, err := e.read(input, header.Extends)
+		if err != nil {
+			return nil, err
+		}
+		base := make(TableOperations)
+		for _, extendedDefinition := range extended {
+			expanded, err := e.expandExtended(input, extendedDefinition)
+			if err != nil {
+				return nil, err
+			}
+			base.merge(expanded)
+		}
+
+		e.extensions = append(e.extensions, fixtureExtension{path: input.Path, extends: header.Extends, base: base, patches: own})
+		extending := make(TableOperations)
+		extending.merge(base)
+		extending.merge(own)
+		own = extending
+	}
+	operations.merge(own)
+
+	return operations, nil
+}
+
+// render executes the template of templated fixture, so its header can be read, and returns the decoder
+// of rendered contents
+func (e *fixturesExpander) render(input Definition) (Definition, FixturesDecoder, error) {
+	templateDecoder, ok := e.tester.FixturesDecoder.(*TemplateFixturesDecoder)
+	if !ok {
+		return input, e.tester.FixturesDecoder, nil
+	}
+
+	rendered, err := templateDecoder.Render(input)
+
+	return rendered, templateDecoder.Decoder, err
+}
+
+// expandExtended returns operations of the fixture extended by input. A fixture already loaded on its own
+// is taken over by input, so its items are written once, patched.
+func (e *fixturesExpander) expandExtended(input, extended Definition) (TableOperations, error) {
+	if extendingPath, ok := e.extendedBy[extended.Path]; ok {
+		return nil, errors.Errorf("fixtures: '%s' is extended by both '%s' and '%s'", extended.Path, extendingPath, input.Path)
+	}
+	e.extendedBy[extended.Path] = input.Path
+
+	if pending, ok := e.pending[extended.Path]; ok {
+		delete(e.pending, extended.Path)
+		return pending, nil
+	}
+	if e.loaded[extended.Path] && !e.expanding[extended.Path] {
+		return nil, errors.Errorf("fixtures: '%s' extended by '%s' is already included by another fixture", extended.Path, input.Path)
+	}
+
+	return e.expand(extended)
+}
+
+func (e *fixturesExpander) read(input Definition, name string) ([]Definition, error) {
+	definitions, err := e.tester.FixturesLoader.ReadDefinitions(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fixtures: cannot load '%s' required by '%s'", name, input.Path)
+	}
+
+	return definitions, nil
+}
+
+// patchItems updates put items of base with attributes of puts of the same primary key and marks these puts
+// as patched, so they're not written on their own. Puts patched before are skipped in base.
+// A patched item takes the origin of its patch, so it's reported at the fixture that changed it last.
+func (t *DynamoTester) patchItems(base, patches TableOperations, origins map[*Operation]itemOrigin, patched map[*Operation]bool) error {
+	tableNames := make([]string, 0, len(patches))
+	for tableName := range patches {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		keyNames, err := t.keyNames(tableName)
+		if err != nil {
+			return err
+		}
+
+		baseItems := make(map[string]*Operation)
+		for _, operation := range base[tableName] {
+			if operation.Op != OperationPut || patched[operation] {
+				continue
+			}
+			if key, ok := itemKey(operation.Item, keyNames); ok {
+				baseItems[key] = operation
+			}
+		}
+
+		for _, operation := range patches[tableName] {
+			if operation.Op != OperationPut {
+				continue
+			}
+			key, ok := itemKey(operation.Item, keyNames)
+			baseOperation, found := baseItems[key]
+			if !ok || !found {
+				continue
+			}
+
+			for name, value := range operation.Item {
+				baseOperation.Item[name] = value
+			}
+			origins[baseOperation] = origins[operation]
+			patched[operation] = true
+		}
+	}
+
+	return nil
+}
+
+// applyOverrides patches items matching keys of overrides, every override has to match at least one item
+func applyOverrides(operations TableOperations, overrides []fixtureOverride) error {
+	for _, override := range overrides {
+		key, err := dynamodbattribute.MarshalMap(override.key)
+		if err != nil {
+			return errors.Wrapf(err, "fixtures: cannot marshal key of override of table '%s'", override.tableName)
+		}
+		keyNames := make([]string, 0, len(key))
+		for name := range key {
+			keyNames = append(keyNames, name)
+		}
+		sort.Strings(keyNames)
+		expectedKey, _ := itemKey(key, keyNames)
+
+		patch := make(map[string]*dynamodb.AttributeValue, len(override.patch))
+		for name, value := range override.patch {
+			if value == nil {
+				patch[name] = nil
+				continue
+			}
+			patch[name], err = dynamodbattribute.Marshal(value)
+			if err != nil {
+				return errors.Wrapf(err, "fixtures: cannot marshal attribute '%s' of override of table '%s'", name, override.tableName)
+			}
+		}
+
+		found := false
+		for _, operation := range operations[override.tableName] {
+			if operation.Op != OperationPut {
+				continue
+			}
+			item := operation.Item
+			if actualKey, ok := itemKey(item, keyNames); !ok || actualKey != expectedKey {
+				continue
+			}
+
+			found = true
+			for name, value := range patch {
+				if value == nil {
+					delete(item, name)
+					continue
+				}
+				item[name] = value
+			}
+		}
+		if !found {
+			return errors.Errorf("fixtures: override of table '%s' matches no item of key %v", override.tableName, override.key)
+		}
+	}
+
+	return nil
+}
+
+// keyNames returns names of primary key attributes of the table according to its migration
+func (t *DynamoTester) keyNames(tableName string) ([]string, error) {
+	tableDefinition, err := t.Migrator.Definition(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	keyNames := make([]string, 0, len(tableDefinition.KeySchema))
+	for _, element := range tableDefinition.KeySchema {
+		keyNames = append(keyNames, *element.AttributeName)
+	}
+
+	return keyNames, nil
+}
+
+// itemKey returns comparable representation of given attributes of the item, false if any of them is missing
+func itemKey(item map[string]*dynamodb.AttributeValue, keyNames []string) (string, bool) {
+	var b strings.Builder
+	for _, name := range keyNames {
+		value, ok := item[name]
+		if !ok || value == nil {
+			return "", false
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(value.String())
+		b.WriteByte(';')
+	}
+
+	return b.String(), true
+}
@@ -0,0 +1,185 @@
+package dynamotest_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamoTesterLoadFixturesIncludesFixtures(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createFixturesDynamoTester(dynamoSvc, createSampleIncludingFixtures())
+
+	err := tester.LoadFixtures("only")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"Abc", "Bca", "Cde"}, namesOf(dynamoSvc.items(tester.TableNameFor("tableName"))))
+}
+
+func TestDynamoTesterLoadFixturesExtendsFixture(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createFixturesDynamoTester(dynamoSvc, createSampleIncludingFixtures())
+
+	err := tester.LoadFixtures("child")
+
+	require.NoError(t, err)
+	items := dynamoSvc.items(tester.TableNameFor("tableName"))
+	require.Equal(t, []string{"Abc", "Changed", "New"}, namesOf(items))
+	require.Equal(t, "3", aws.StringValue(items[1]["Age"].N))
+}
+
+func TestDynamoTesterLoadAllFixturesExtendsFixtureOnce(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	fixtures := createSampleIncludingFixtures()
+	tester := createFixturesDynamoTester(dynamoSvc, staticLoader{"base": fixtures["base"], "scenario": fixtures["child"]})
+
+	err := tester.LoadFixtures()
+
+	require.NoError(t, err)
+	items := dynamoSvc.items(tester.TableNameFor("tableName"))
+	require.Equal(t, []string{"Abc", "Changed", "New"}, namesOf(items))
+	require.Equal(t, "3", aws.StringValue(items[1]["Age"].N))
+}
+
+func TestDynamoTesterLoadFixturesIncludesFromTemplatedFixture(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createFixturesDynamoTester(dynamoSvc, createSampleIncludingFixtures())
+	loader := tester.FixturesLoader.(staticLoader)
+	loader["templated"] = []byte(`{"include": ["base"], "table": "otherTable", "items": [{{ range $i := times 2 }}{{ if $i }},{{ end }}{"ID": {{ seq "other" }}}{{ end }}]}`)
+	tester.FixturesDecoder = dynamotest.NewTemplateFixturesDecoder(tester.FixturesDecoder, new(dynamotest.RealClock))
+
+	err := tester.LoadFixtures("templated")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"Abc", "Bca"}, namesOf(dynamoSvc.items(tester.TableNameFor("tableName"))))
+	require.Len(t, dynamoSvc.items(tester.TableNameFor("otherTable")), 2)
+}
+
+func TestDynamoTesterLoadFixturesWithOverride(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createFixturesDynamoTester(dynamoSvc, createSampleIncludingFixtures())
+
+	err := tester.LoadFixturesWith(
+		[]string{"extra"},
+		dynamotest.WithOverride("tableName", map[string]interface{}{"ID": 2}, map[string]interface{}{"Name": "Overridden", "Age": nil}),
+		dynamotest.WithOverride("tableName", map[string]interface{}{"ID": 3}, map[string]interface{}{"Active": true}),
+	)
+
+	require.NoError(t, err)
+	items := dynamoSvc.items(tester.TableNameFor("tableName"))
+	require.Equal(t, []string{"Abc", "Overridden", "Cde"}, namesOf(items))
+	require.NotContains(t, items[1], "Age")
+	require.Equal(t, &dynamodb.AttributeValue{BOOL: aws.Bool(true)}, items[2]["Active"])
+}
+
+func TestDynamoTesterLoadFixturesWithOverrideOfReferencedKey(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createFixturesDynamoTester(dynamoSvc, createSampleIncludingFixtures())
+
+	err := tester.LoadFixturesWith(
+		[]string{"referencing"},
+		dynamotest.WithOverride("tableName", map[string]interface{}{"ID": 7}, map[string]interface{}{"Name": "Overridden"}),
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"Overridden"}, namesOf(dynamoSvc.items(tester.TableNameFor("tableName"))))
+}
+
+func TestDynamoTesterLoadFixturesExtendsItemOfReferencedKey(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createFixturesDynamoTester(dynamoSvc, createSampleIncludingFixtures())
+
+	err := tester.LoadFixtures("referencingChild")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"Changed"}, namesOf(dynamoSvc.items(tester.TableNameFor("tableName"))))
+}
+
+func TestDynamoTesterLoadFixturesReportsInvalidIncludes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fixtures []string
+		options  []dynamotest.LoadOption
+		expected string
+	}{
+		{
+			name:     "circular extends",
+			fixtures: []string{"loop"},
+			expected: "fixtures: 'loop.json' extends itself",
+		},
+		{
+			name:     "missing include",
+			fixtures: []string{"broken"},
+			expected: "fixtures: cannot load 'unknown' required by 'broken.json'",
+		},
+		{
+			name:     "invalid include",
+			fixtures: []string{"invalid"},
+			expected: "invalid.json:1:13: invalid include",
+		},
+		{
+			name:     "unreadable include",
+			fixtures: []string{"unparsable"},
+			expected: "unparsable.json: cannot read include of unparsable fixture",
+		},
+		{
+			name:     "fixture extended twice",
+			fixtures: []string{"child", "sibling"},
+			expected: "fixtures: 'base.json' is extended by both 'child.json' and 'sibling.json'",
+		},
+		{
+			name:     "extended fixture included elsewhere",
+			fixtures: []string{"extra", "child"},
+			expected: "fixtures: 'base.json' extended by 'child.json' is already included by another fixture",
+		},
+		{
+			name:     "unmatched override",
+			fixtures: []string{"base"},
+			options: []dynamotest.LoadOption{
+				dynamotest.WithOverride("tableName", map[string]interface{}{"ID": 5}, map[string]interface{}{"Name": "None"}),
+			},
+			expected: "fixtures: override of table 'tableName' matches no item of key map[ID:5]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tester := createFixturesDynamoTester(newFakeDynamoDB(), createSampleIncludingFixtures())
+			loader := tester.FixturesLoader.(staticLoader)
+			loader["broken"] = []byte(`{"include": ["unknown"]}`)
+			loader["invalid"] = []byte(`{"include": {"base": true}}`)
+			loader["unparsable"] = []byte(`{"include": ["base"], "table": "tableName", "items": [{{ range $i := times 2 }}{"ID": 1}{{ end }}]}`)
+			loader["sibling"] = []byte(`{"extends": "base", "table": "tableName", "items": [{"ID": 5, "Name": "Other"}]}`)
+
+			err := tester.LoadFixturesWith(tc.fixtures, tc.options...)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func createSampleIncludingFixtures() staticLoader {
+	return staticLoader{
+		"base":             []byte(`{"table": "tableName", "items": [{"ID": 1, "Name": "Abc"}, {"ID": 2, "Name": "Bca", "Age": 3}]}`),
+		"extra":            []byte(`{"include": ["base"], "table": "tableName", "items": [{"ID": 3, "Name": "Cde"}]}`),
+		"child":            []byte(`{"extends": "base", "table": "tableName", "items": [{"ID": 2, "Name": "Changed"}, {"ID": 4, "Name": "New"}]}`),
+		"only":             []byte(`{"include": ["base", "extra"]}`),
+		"loop":             []byte(`{"extends": "loop", "table": "tableName", "items": []}`),
+		"aliased":          []byte(`{"table": "otherTable", "items": [{"$id": "alice", "ID": 7}]}`),
+		"referencing":      []byte(`{"include": ["aliased"], "table": "tableName", "items": [{"ID": "{{ref alice.ID}}", "Name": "Abc"}]}`),
+		"referencingChild": []byte(`{"extends": "referencing", "table": "tableName", "items": [{"ID": 7, "Name": "Changed"}]}`),
+	}
+}
+
+func namesOf(items []map[string]*dynamodb.AttributeValue) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, aws.StringValue(item["Name"].S))
+	}
+
+	return names
+}
@@ -1,20 +1,28 @@
 package dynamotest
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/pkg/errors"
 )
 
 type Migrator struct {
-	definitions       map[string]*dynamodb.CreateTableInput
+	// definitions are loaded once, all of them or none, mutex guards them together with createdTables
+	definitions map[string]*TableDefinition
+	// createdTables are tables created by Creator, tables that existed before are not tracked, so Teardown keeps them
+	createdTables []string
+	mutex         sync.Mutex
+
 	MigrationsLoader  DefinitionsLoader
 	MigrationsDecoder MigrationDecoder
 	TableNameResolver TableNameResolver
 	Creator           TableCreator
 }
 
-func NewDefaultMigrator(dynamoSvc *dynamodb.DynamoDB, migrationsPath string) *Migrator {
+func NewDefaultMigrator(dynamoSvc dynamodbiface.DynamoDBAPI, migrationsPath string) *Migrator {
 	return &Migrator{
 		MigrationsLoader:  NewJSONFilesystemReader(migrationsPath),
 		MigrationsDecoder: new(JSONMigrationDecoder),
@@ -24,18 +32,21 @@ func NewDefaultMigrator(dynamoSvc *dynamodb.DynamoDB, migrationsPath string) *Mi
 }
 
 func (m *Migrator) MigrateTables(tableNames ...string) error {
-	err := m.loadDefinitions()
+	definitions, err := m.loadDefinitions()
 	if err != nil {
 		return errors.Wrap(err, "migrate: cannot load migration definitions")
 	}
 
 	if len(tableNames) == 0 {
-		tableNames = m.prepareTableNames()
+		tableNames = prepareTableNames(definitions)
 	}
 
 	for _, tableName := range tableNames {
-		if tableDefinition, ok := m.definitions[tableName]; ok {
-			err := m.Creator.CreateTable(tableDefinition)
+		if tableDefinition, ok := definitions[tableName]; ok {
+			created, err := m.createTable(tableDefinition)
+			if created {
+				m.trackTable(*tableDefinition.TableName)
+			}
 			if err != nil {
 				return errors.Wrapf(err, "migrate: cannot create table %s(%s)", tableName, *tableDefinition.TableName)
 			}
@@ -45,37 +56,114 @@ func (m *Migrator) MigrateTables(tableNames ...string) error {
 	return nil
 }
 
-func (m *Migrator) loadDefinitions() error {
-	if len(m.definitions) > 0 {
-		return nil
+// Definition returns the migration of given table, with its name already resolved
+func (m *Migrator) Definition(tableName string) (*TableDefinition, error) {
+	definitions, err := m.loadDefinitions()
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate: cannot load migration definitions")
 	}
 
-	m.definitions = make(map[string]*dynamodb.CreateTableInput)
-	tablesDefinitions, err := m.MigrationsLoader.ReadDefinitions()
+	tableDefinition, ok := definitions[tableName]
+	if !ok {
+		return nil, &NoMigrationError{TableName: tableName}
+	}
+
+	return tableDefinition, nil
+}
+
+// NoMigrationError is returned by Migrator.Definition for tables none of migrations defines
+type NoMigrationError struct {
+	TableName string
+}
 
+func (e *NoMigrationError) Error() string {
+	return fmt.Sprintf("migrate: no migration of table '%s'", e.TableName)
+}
+
+// createTable creates the table with Creator and tells whether it has been created rather than found existing
+func (m *Migrator) createTable(tableDefinition *TableDefinition) (bool, error) {
+	if reporter, ok := m.Creator.(TableCreationReporter); ok {
+		return reporter.CreateTableIfNotExists(tableDefinition)
+	}
+
+	err := m.Creator.CreateTable(tableDefinition)
+
+	return err == nil, err
+}
+
+// CreatedTables returns resolved names of all tables created by the migrator, in order of creation
+func (m *Migrator) CreatedTables() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := make([]string, len(m.createdTables))
+	copy(result, m.createdTables)
+
+	return result
+}
+
+func (m *Migrator) trackTable(tableName string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, t := range m.createdTables {
+		if t == tableName {
+			return
+		}
+	}
+
+	m.createdTables = append(m.createdTables, tableName)
+}
+
+func (m *Migrator) untrackTable(tableName string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, t := range m.createdTables {
+		if t == tableName {
+			m.createdTables = append(m.createdTables[:i], m.createdTables[i+1:]...)
+			return
+		}
+	}
+}
+
+// loadDefinitions decodes all migrations on the first call, a failed load leaves no definitions behind
+func (m *Migrator) loadDefinitions() (map[string]*TableDefinition, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.definitions != nil {
+		return m.definitions, nil
+	}
+
+	tablesDefinitions, err := m.MigrationsLoader.ReadDefinitions()
 	if err != nil {
-		return errors.Wrap(err, "migrate: cannot load migration files")
+		return nil, errors.Wrap(err, "migrate: cannot load migration files")
 	}
 
+	definitions := make(map[string]*TableDefinition)
 	for _, d := range tablesDefinitions {
-		createTableInput, err := m.MigrationsDecoder.Decode(d)
+		tables, err := m.MigrationsDecoder.Decode(d)
 		if err != nil {
-			return errors.Wrap(err, "migrate: cannot decode migration file")
+			return nil, errors.Wrap(err, "migrate: cannot decode migration file")
 		}
 
-		tableName := createTableInput.TableName
-		newTableName := m.TableNameResolver.Resolve(*createTableInput.TableName)
-		createTableInput.TableName = aws.String(newTableName)
+		for _, table := range tables {
+			tableName := table.TableName
+			newTableName := m.TableNameResolver.Resolve(*table.TableName)
+			table.TableName = aws.String(newTableName)
 
-		m.definitions[*tableName] = createTableInput
+			definitions[*tableName] = table
+		}
 	}
+	m.definitions = definitions
 
-	return nil
+	return definitions, nil
 }
 
-func (m *Migrator) prepareTableNames() []string {
+func prepareTableNames(definitions map[string]*TableDefinition) []string {
 	result := make([]string, 0)
-	for tableName := range m.definitions {
+	for tableName := range definitions {
 		result = append(result, tableName)
 	}
 
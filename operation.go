@@ -0,0 +1,132 @@
+package dynamotest
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+// Kinds of operations of fixtures items, chosen with "$op" attribute
+const (
+	OperationPut    = "put"
+	OperationDelete = "delete"
+	OperationUpdate = "update"
+)
+
+// Operation is a change of a single item made by a fixture
+type Operation struct {
+	// Op is either OperationPut, OperationDelete or OperationUpdate
+	Op string
+	// Key is the primary key of the item removed by OperationDelete
+	Key map[string]*dynamodb.AttributeValue
+	// Item holds attributes of the item written by OperationPut, or the primary key and attributes set by OperationUpdate.
+	// Fixtures don't know key schemas of their tables, so the key of an update is told apart by DynamoTester.
+	Item map[string]*dynamodb.AttributeValue
+	// Remove lists attributes removed by OperationUpdate
+	Remove []string
+	// Alias names the item of put or update declared with "$id", so other items can refer to its attributes
+	Alias string
+}
+
+// attributes returns the item of put and update, or the key of delete
+func (o *Operation) attributes() map[string]*dynamodb.AttributeValue {
+	if o.Op == OperationDelete {
+		return o.Key
+	}
+
+	return o.Item
+}
+
+// splitUpdate returns the key of the updated item and attributes set by the update
+func (o *Operation) splitUpdate(keyNames []string) (key, attributes map[string]*dynamodb.AttributeValue) {
+	key = make(map[string]*dynamodb.AttributeValue, len(keyNames))
+	attributes = make(map[string]*dynamodb.AttributeValue, len(o.Item))
+	for name, value := range o.Item {
+		attributes[name] = value
+	}
+	for _, name := range keyNames {
+		if value, ok := attributes[name]; ok {
+			key[name] = value
+			delete(attributes, name)
+		}
+	}
+
+	return key, attributes
+}
+
+// writeRequest converts put and delete to dynamodb.WriteRequest, updates cannot be written in batches
+func (o *Operation) writeRequest() (*dynamodb.WriteRequest, error) {
+	switch o.Op {
+	case OperationPut:
+		return &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: o.Item}}, nil
+	case OperationDelete:
+		return &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: o.Key}}, nil
+	default:
+		return nil, errors.Errorf("%s operation cannot be written as a write request", o.Op)
+	}
+}
+
+// TableOperations is a collection of Operation grouped by table.
+// It supersedes TableWriteRequests, which cannot express updates.
+type TableOperations map[string][]*Operation
+
+// merge appends operations of other collection, keeping their order
+func (o TableOperations) merge(other TableOperations) {
+	for tableName, operations := range other {
+		o[tableName] = append(o[tableName], operations...)
+	}
+}
+
+// WriteRequests converts puts and deletes to TableWriteRequests, it fails on updates
+func (o TableOperations) WriteRequests() (TableWriteRequests, error) {
+	writeRequests := make(TableWriteRequests, len(o))
+	for tableName, operations := range o {
+		for i, operation := range operations {
+			writeRequest, err := operation.writeRequest()
+			if err != nil {
+				return nil, errors.Wrapf(err, "item %d of table '%s'", i, tableName)
+			}
+			writeRequests[tableName] = append(writeRequests[tableName], writeRequest)
+		}
+	}
+
+	return writeRequests, nil
+}
+
+// OperationsFromWriteRequests converts write requests to put and delete operations
+func OperationsFromWriteRequests(writeRequests TableWriteRequests) TableOperations {
+	operations := make(TableOperations, len(writeRequests))
+	for tableName, requests := range writeRequests {
+		for _, writeRequest := range requests {
+			operation := &Operation{Op: OperationPut}
+			if writeRequest.PutRequest != nil {
+				operation.Item = writeRequest.PutRequest.Item
+			} else {
+				operation.Op = OperationDelete
+				operation.Key = writeRequest.DeleteRequest.Key
+			}
+			operations[tableName] = append(operations[tableName], operation)
+		}
+	}
+
+	return operations
+}
+
+// OperationsDecoder is implemented by fixtures decoders able to decode every kind of operations, including updates.
+// DynamoTester prefers it over Decode of FixturesDecoder.
+type OperationsDecoder interface {
+	DecodeOperations(input []Definition) (TableOperations, error)
+}
+
+// decodeOperations decodes fixtures with DecodeOperations of the decoder, if it implements OperationsDecoder
+func decodeOperations(decoder FixturesDecoder, input []Definition) (TableOperations, error) {
+	if operationsDecoder, ok := decoder.(OperationsDecoder); ok {
+		return operationsDecoder.DecodeOperations(input)
+	}
+
+	writeRequests, err := decoder.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return OperationsFromWriteRequests(writeRequests), nil
+}
@@ -0,0 +1,329 @@
+package dynamotest
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMigrationsPath = "migrations"
+	defaultFixturesPath   = "fixtures"
+)
+
+// Option configures DynamoTester created with NewDynamoTester or New
+type Option func(*options) error
+
+type options struct {
+	migrationsPath    string
+	fixturesPath      string
+	migrationsLoader  DefinitionsLoader
+	fixturesLoader    DefinitionsLoader
+	migrationsDecoder MigrationDecoder
+	fixturesDecoder   FixturesDecoder
+	tableNameResolver TableNameResolver
+	creator           TableCreator
+	cleaner           TableCleaner
+	batchWriter       BatchWriter
+	updater           ItemUpdater
+	waiter            TableWaiter
+	templateClock     Clock
+	templated         bool
+	seed              *int64
+	testName          string
+}
+
+// WithMigrationsPath sets the directory JSON migrations are loaded from, "migrations" by default.
+// It cannot be combined with WithMigrationsLoader.
+func WithMigrationsPath(path string) Option {
+	return func(o *options) error {
+		o.migrationsPath = path
+		return nil
+	}
+}
+
+// WithFixturesPath sets the directory JSON fixtures are loaded from, "fixtures" by default.
+// It cannot be combined with WithFixturesLoader.
+func WithFixturesPath(path string) Option {
+	return func(o *options) error {
+		o.fixturesPath = path
+		return nil
+	}
+}
+
+// WithMigrationsLoader replaces the loader of migrations
+func WithMigrationsLoader(loader DefinitionsLoader) Option {
+	return func(o *options) error {
+		if loader == nil {
+			return errors.New("options: migrations loader cannot be nil")
+		}
+		o.migrationsLoader = loader
+		return nil
+	}
+}
+
+// WithFixturesLoader replaces the loader of fixtures
+func WithFixturesLoader(loader DefinitionsLoader) Option {
+	return func(o *options) error {
+		if loader == nil {
+			return errors.New("options: fixtures loader cannot be nil")
+		}
+		o.fixturesLoader = loader
+		return nil
+	}
+}
+
+// WithMigrationsDecoder replaces the decoder of migrations
+func WithMigrationsDecoder(decoder MigrationDecoder) Option {
+	return func(o *options) error {
+		if decoder == nil {
+			return errors.New("options: migrations decoder cannot be nil")
+		}
+		o.migrationsDecoder = decoder
+		return nil
+	}
+}
+
+// WithFixturesDecoder replaces the decoder of fixtures
+func WithFixturesDecoder(decoder FixturesDecoder) Option {
+	return func(o *options) error {
+		if decoder == nil {
+			return errors.New("options: fixtures decoder cannot be nil")
+		}
+		o.fixturesDecoder = decoder
+		return nil
+	}
+}
+
+// WithTableNameResolver replaces the resolver of table names used both by the tester and its migrator.
+// Resolver is memoized, unless it's a MemoizedTableNameResolver already, so TableNameFor always returns the same name.
+func WithTableNameResolver(resolver TableNameResolver) Option {
+	return func(o *options) error {
+		if resolver == nil {
+			return errors.New("options: table name resolver cannot be nil")
+		}
+		o.tableNameResolver = resolver
+		return nil
+	}
+}
+
+// WithTableCreator replaces the creator of tables used by the migrator
+func WithTableCreator(creator TableCreator) Option {
+	return func(o *options) error {
+		if creator == nil {
+			return errors.New("options: table creator cannot be nil")
+		}
+		o.creator = creator
+		return nil
+	}
+}
+
+// WithCleaner replaces the cleaner run on every table before fixtures are loaded
+func WithCleaner(cleaner TableCleaner) Option {
+	return func(o *options) error {
+		if cleaner == nil {
+			return errors.New("options: cleaner cannot be nil")
+		}
+		o.cleaner = cleaner
+		return nil
+	}
+}
+
+// WithBatchWriter replaces the writer of fixtures items
+func WithBatchWriter(writer BatchWriter) Option {
+	return func(o *options) error {
+		if writer == nil {
+			return errors.New("options: batch writer cannot be nil")
+		}
+		o.batchWriter = writer
+		return nil
+	}
+}
+
+// WithItemUpdater replaces the updater applying "update" operations of fixtures
+func WithItemUpdater(updater ItemUpdater) Option {
+	return func(o *options) error {
+		if updater == nil {
+			return errors.New("options: item updater cannot be nil")
+		}
+		o.updater = updater
+		return nil
+	}
+}
+
+// WithWaiter replaces the table waiter used by the tester, the default table creator and the default cleaner
+func WithWaiter(waiter TableWaiter) Option {
+	return func(o *options) error {
+		if waiter == nil {
+			return errors.New("options: waiter cannot be nil")
+		}
+		o.waiter = waiter
+		return nil
+	}
+}
+
+// WithTemplateClock replaces the clock of "now" in templated fixtures, RealClock by default.
+// Use FakeClock to make fixtures reproducible. It implies WithTemplatedFixtures.
+// Table names keep using the real time, so testers don't share tables.
+func WithTemplateClock(clock Clock) Option {
+	return func(o *options) error {
+		if clock == nil {
+			return errors.New("options: template clock cannot be nil")
+		}
+		o.templated = true
+		o.templateClock = clock
+		return nil
+	}
+}
+
+// WithTemplatedFixtures executes fixtures as templates before decoding them, see TemplateFixturesDecoder
+func WithTemplatedFixtures() Option {
+	return func(o *options) error {
+		o.templated = true
+		return nil
+	}
+}
+
+// WithSeed sets the seed of random values in templated fixtures, replaying a run that logged it.
+// It implies WithTemplatedFixtures.
+func WithSeed(seed int64) Option {
+	return func(o *options) error {
+		o.templated = true
+		o.seed = &seed
+		return nil
+	}
+}
+
+// withTestName makes the default table name resolver derive names from the test name
+func withTestName(name string) Option {
+	return func(o *options) error {
+		o.testName = name
+		return nil
+	}
+}
+
+// NewDynamoTester creates DynamoTester configured with given options.
+// Every dependency that is not replaced by an option gets the same default as in NewDefaultDynamoTester.
+func NewDynamoTester(dynamoSvc dynamodbiface.DynamoDBAPI, opts ...Option) (*DynamoTester, error) {
+	if dynamoSvc == nil {
+		return nil, errors.New("options: DynamoDB client cannot be nil")
+	}
+
+	o, err := collectOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDynamoTester(dynamoSvc, o), nil
+}
+
+func collectOptions(opts []Option) (*options, error) {
+	o := new(options)
+	for _, opt := range opts {
+		err := opt(o)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.migrationsPath != "" && o.migrationsLoader != nil {
+		return nil, errors.New("options: WithMigrationsPath cannot be combined with WithMigrationsLoader")
+	}
+	if o.fixturesPath != "" && o.fixturesLoader != nil {
+		return nil, errors.New("options: WithFixturesPath cannot be combined with WithFixturesLoader")
+	}
+
+	return o, nil
+}
+
+func buildDynamoTester(dynamoSvc dynamodbiface.DynamoDBAPI, o *options) *DynamoTester {
+	waiter := o.waiter
+	if waiter == nil {
+		waiter = NewDescribeTableWaiter(dynamoSvc)
+	}
+
+	resolver := o.tableNameResolver
+	if resolver == nil && o.testName != "" {
+		resolver = NewTestNameTableNameResolver(o.testName, new(RealClock))
+	}
+	if resolver == nil {
+		resolver = NewTimestampTableNameResolver(new(RealClock))
+	}
+	if _, ok := resolver.(*MemoizedTableNameResolver); !ok {
+		resolver = NewMemoizedTableNameResolver(resolver)
+	}
+
+	migrationsLoader := o.migrationsLoader
+	if migrationsLoader == nil {
+		migrationsLoader = NewJSONFilesystemReader(pathOrDefault(o.migrationsPath, defaultMigrationsPath))
+	}
+	fixturesLoader := o.fixturesLoader
+	if fixturesLoader == nil {
+		fixturesLoader = NewJSONFilesystemReader(pathOrDefault(o.fixturesPath, defaultFixturesPath))
+	}
+
+	migrationsDecoder := o.migrationsDecoder
+	if migrationsDecoder == nil {
+		migrationsDecoder = new(JSONMigrationDecoder)
+	}
+	fixturesDecoder := o.fixturesDecoder
+	if fixturesDecoder == nil {
+		fixturesDecoder = NewJSONFixturesDecoder()
+	}
+	if o.templated {
+		templateClock := o.templateClock
+		if templateClock == nil {
+			templateClock = new(RealClock)
+		}
+		templateDecoder := NewTemplateFixturesDecoder(fixturesDecoder, templateClock)
+		if o.seed != nil {
+			templateDecoder.Seed = *o.seed
+		}
+		fixturesDecoder = templateDecoder
+	}
+
+	creator := o.creator
+	if creator == nil {
+		defaultCreator := NewDefaultTableCreator(dynamoSvc)
+		defaultCreator.Waiter = waiter
+		creator = defaultCreator
+	}
+	cleaner := o.cleaner
+	if cleaner == nil {
+		defaultCleaner := NewWholeTableDynamoCleaner(dynamoSvc)
+		defaultCleaner.Waiter = waiter
+		cleaner = defaultCleaner
+	}
+	batchWriter := o.batchWriter
+	if batchWriter == nil {
+		batchWriter = NewChunkedBatchWriter(dynamoSvc)
+	}
+	updater := o.updater
+	if updater == nil {
+		updater = NewDefaultItemUpdater(dynamoSvc)
+	}
+
+	return &DynamoTester{
+		dynamoDbSvc: dynamoSvc,
+		Migrator: &Migrator{
+			MigrationsLoader:  migrationsLoader,
+			MigrationsDecoder: migrationsDecoder,
+			TableNameResolver: resolver,
+			Creator:           creator,
+		},
+		FixturesLoader:    fixturesLoader,
+		FixturesDecoder:   fixturesDecoder,
+		TableNameResolver: resolver,
+		Cleaner:           cleaner,
+		BatchWriter:       batchWriter,
+		Updater:           updater,
+		Waiter:            waiter,
+	}
+}
+
+func pathOrDefault(path, defaultPath string) string {
+	if path == "" {
+		return defaultPath
+	}
+
+	return path
+}
@@ -0,0 +1,85 @@
+package dynamotest_test
+
+import (
+	"testing"
+
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDynamoTester(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	fixtures := createSampleFixturesBytes()
+	waiter := createFastWaiter(dynamoSvc)
+	cleaner := dynamotest.NewScanDeleteTableCleaner(dynamoSvc)
+
+	tester, err := dynamotest.NewDynamoTester(
+		dynamoSvc,
+		dynamotest.WithMigrationsLoader(staticLoader{"tableName": createSampleMigrationBytes()}),
+		dynamotest.WithFixturesLoader(staticLoader{"first": fixtures[0], "second": fixtures[1]}),
+		dynamotest.WithTableNameResolver(dynamotest.NewRandomTableNameResolver()),
+		dynamotest.WithCleaner(cleaner),
+		dynamotest.WithWaiter(waiter),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, tester.LoadFixtures())
+	require.Same(t, tester.TableNameResolver, tester.Migrator.TableNameResolver)
+	require.IsType(t, &dynamotest.MemoizedTableNameResolver{}, tester.TableNameResolver)
+	require.Same(t, cleaner, tester.Cleaner)
+	require.Same(t, waiter, tester.Waiter)
+	require.Same(t, waiter, tester.Migrator.Creator.(*dynamotest.DefaultTableCreator).Waiter)
+	require.Len(t, dynamoSvc.items(tester.TableNameFor("tableName")), 3)
+}
+
+func TestNewDynamoTesterRejectsInvalidOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		client  *fakeDynamoDB
+		options []dynamotest.Option
+	}{
+		{
+			name:   "missing client",
+			client: nil,
+		},
+		{
+			name:   "migrations path and loader",
+			client: newFakeDynamoDB(),
+			options: []dynamotest.Option{
+				dynamotest.WithMigrationsPath("migrations"),
+				dynamotest.WithMigrationsLoader(staticLoader{}),
+			},
+		},
+		{
+			name:   "fixtures path and loader",
+			client: newFakeDynamoDB(),
+			options: []dynamotest.Option{
+				dynamotest.WithFixturesLoader(staticLoader{}),
+				dynamotest.WithFixturesPath("fixtures"),
+			},
+		},
+		{
+			name:    "nil cleaner",
+			client:  newFakeDynamoDB(),
+			options: []dynamotest.Option{dynamotest.WithCleaner(nil)},
+		},
+		{
+			name:    "nil batch writer",
+			client:  newFakeDynamoDB(),
+			options: []dynamotest.Option{dynamotest.WithBatchWriter(nil)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			if tc.client == nil {
+				_, err = dynamotest.NewDynamoTester(nil, tc.options...)
+			} else {
+				_, err = dynamotest.NewDynamoTester(tc.client, tc.options...)
+			}
+
+			require.Error(t, err)
+		})
+	}
+}
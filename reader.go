@@ -2,74 +2,249 @@ package dynamotest
 
 import (
 	"fmt"
+	"io/fs"
 	"io/ioutil"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
 // DefinitionsLoader defines a struct able to read contents of tables definitions
 type DefinitionsLoader interface {
-	ReadDefinitions(names ...string) ([][]byte, error)
+	ReadDefinitions(names ...string) ([]Definition, error)
 }
 
-// FilesystemDirectoryLoader reads files from given directory filtering files by given extension
+// Source describes where a definition has been read from
+type Source struct {
+	// Path is the path of the file as seen by the loader
+	Path string
+	// Name is the logical name of the definition, as accepted by ReadDefinitions
+	Name string
+}
+
+// Definition is the contents of a single migration or fixtures file together with its source
+type Definition struct {
+	Source
+	Contents []byte
+}
+
+// FilesystemDirectoryLoader reads files from given directory filtering files by given extensions.
+// When no names are given, it reads all matching files from the directory and its subdirectories
+// in lexical order of their slash-separated paths relative to the directory, e.g. "a.json", "b.json", "b/a.json".
 type FilesystemDirectoryLoader struct {
-	dir       string
-	extension string
+	dir        string
+	extensions []string
+	// Include limits files read from the whole directory to the ones matching any of the patterns.
+	// Patterns are matched against slash-separated paths relative to the directory, using path.Match syntax
+	// extended with "**" standing for any number of directories, e.g. "users/**/*.json".
+	Include []string
+	// Exclude skips files read from the whole directory matching any of the patterns, see Include
+	Exclude []string
 }
 
-func NewFilesystemDirectoryLoader(dir string, extension string) *FilesystemDirectoryLoader {
-	return &FilesystemDirectoryLoader{dir: dir, extension: extension}
+// NewFilesystemDirectoryLoader creates FilesystemDirectoryLoader reading files with any of given extensions,
+// e.g. "json", "yml" and "yaml" for directories mixing JSON and YAML files.
+// A name passed to ReadDefinitions is resolved to the file with the first extension that exists.
+func NewFilesystemDirectoryLoader(dir string, extensions ...string) *FilesystemDirectoryLoader {
+	return &FilesystemDirectoryLoader{dir: dir, extensions: extensions}
 }
 
 // NewJSONFilesystemReader creates FilesystemDirectoryLoader instance which reads JSON files
 func NewJSONFilesystemReader(dir string) *FilesystemDirectoryLoader {
-	return &FilesystemDirectoryLoader{dir: dir, extension: "json"}
+	return &FilesystemDirectoryLoader{dir: dir, extensions: []string{"json"}}
 }
 
-func (r *FilesystemDirectoryLoader) ReadDefinitions(names ...string) ([][]byte, error) {
-	var files []string
+func (r *FilesystemDirectoryLoader) ReadDefinitions(names ...string) ([]Definition, error) {
+	var files []Source
 	if len(names) == 0 {
 		var err error
-		files, err = listFilesInDir(r.dir, r.extension)
+		files, err = listFilesInDir(r.dir, r.extensions, r.Include, r.Exclude)
 		if err != nil {
 			return nil, errors.Wrap(err, "migrate: cannot read definitions")
 		}
 	} else {
-		files = combineNamesWithDirectory(names, r.dir, r.extension)
+		files = combineNamesWithDirectory(names, r.dir, r.extensions)
 	}
 
-	var result [][]byte
-	for _, fileName := range files {
-		contents, err := ioutil.ReadFile(filepath.Clean(fileName))
+	var result []Definition
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(filepath.Clean(file.Path))
 		if err != nil {
-			return nil, errors.Wrapf(err, "migrate: cannot read file: '%s'", fileName)
+			return nil, errors.Wrapf(err, "migrate: cannot read file: '%s'", file.Path)
 		}
-		result = append(result, contents)
+		result = append(result, Definition{Source: file, Contents: contents})
 	}
 
 	return result, nil
 }
 
-func listFilesInDir(directory, extension string) ([]string, error) {
-	extPattern := fmt.Sprintf("*.%s", extension)
-	fullPath := filepath.Join(directory, extPattern)
-
-	files, err := filepath.Glob(fullPath)
+func listFilesInDir(directory string, extensions, include, exclude []string) ([]Source, error) {
+	relativePaths, err := listMatchingFiles(os.DirFS(directory), ".", extensions, include, exclude)
 	if err != nil {
 		return nil, errors.Wrapf(err, "migrate: cannot load files from migrations path: '%s'", directory)
 	}
 
+	files := make([]Source, 0, len(relativePaths))
+	for _, relativePath := range relativePaths {
+		files = append(files, Source{
+			Path: filepath.Join(directory, filepath.FromSlash(relativePath)),
+			Name: definitionName(relativePath),
+		})
+	}
+
 	return files, nil
 }
 
-func combineNamesWithDirectory(names []string, directory, extension string) []string {
-	var result []string
+// listMatchingFiles walks the directory of fsys and returns slash-separated paths, relative to the directory,
+// of files passing matchesFilters in lexical order. It's shared by FilesystemDirectoryLoader and FSDirectoryLoader.
+func listMatchingFiles(fsys fs.FS, directory string, extensions, include, exclude []string) ([]string, error) {
+	var relativePaths []string
+	err := fs.WalkDir(fsys, directory, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		relativePath := fullPath
+		if directory != "." {
+			relativePath = strings.TrimPrefix(fullPath, directory+"/")
+		}
+
+		ok, err := matchesFilters(relativePath, extensions, include, exclude)
+		if err != nil {
+			return err
+		}
+		if ok {
+			relativePaths = append(relativePaths, relativePath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(relativePaths)
+
+	return relativePaths, nil
+}
+
+// definitionName builds the logical name of a definition from slash-separated path by dropping its extension
+func definitionName(relativePath string) string {
+	return strings.TrimSuffix(relativePath, path.Ext(relativePath))
+}
+
+// matchesFilters tells whether the file with given slash-separated relative path
+// has one of expected extensions, matches any of include patterns (if there are any) and none of exclude patterns
+func matchesFilters(relativePath string, extensions, include, exclude []string) (bool, error) {
+	if !hasAnyExtension(relativePath, extensions) {
+		return false, nil
+	}
+
+	if len(include) > 0 {
+		ok, err := matchesAnyGlob(include, relativePath)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	ok, err := matchesAnyGlob(exclude, relativePath)
+	if err != nil {
+		return false, err
+	}
+
+	return !ok, nil
+}
+
+func matchesAnyGlob(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		ok, err := matchGlob(pattern, name)
+		if err != nil {
+			return false, errors.Wrapf(err, "migrate: invalid pattern '%s'", pattern)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// matchGlob matches slash-separated name against the pattern in path.Match syntax,
+// where "**" segment matches any number (including zero) of path segments
+func matchGlob(pattern, name string) (bool, error) {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(pattern, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				ok, err := matchGlobSegments(pattern[1:], name[i:])
+				if err != nil || ok {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+
+		if len(name) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0, nil
+}
+
+func hasAnyExtension(name string, extensions []string) bool {
+	for _, extension := range extensions {
+		if strings.HasSuffix(name, "."+extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// combineNamesWithDirectory resolves names to paths of files with the first of extensions that exists
+func combineNamesWithDirectory(names []string, directory string, extensions []string) []Source {
+	var result []Source
 	for _, n := range names {
-		fileName := fmt.Sprintf("%s.%s", n, extension)
-		result = append(result, filepath.Join(directory, fileName))
+		filePath := resolveNamedFile(n, extensions, func(fileName string) (string, bool) {
+			filePath := filepath.Join(directory, fileName)
+			_, err := os.Stat(filePath)
+			return filePath, err == nil
+		})
+		result = append(result, Source{Path: filePath, Name: n})
 	}
 
 	return result
 }
+
+// resolveNamedFile builds the path of the named file with the first extension for which the file exists.
+// When none of them exists, the path with the first extension is returned, so reading it reports the error.
+func resolveNamedFile(name string, extensions []string, stat func(fileName string) (string, bool)) string {
+	var firstPath string
+	for i, extension := range extensions {
+		filePath, exists := stat(fmt.Sprintf("%s.%s", name, extension))
+		if i == 0 {
+			firstPath = filePath
+		}
+		if exists {
+			return filePath
+		}
+	}
+
+	return firstPath
+}
@@ -9,14 +9,18 @@ import (
 
 func TestJsonFilesystemLoader(t *testing.T) {
 	loader := dynamotest.NewJSONFilesystemReader("test_resources/")
-	expectedResult := [][]byte{
-		[]byte(`{
+	expectedResult := []dynamotest.Definition{
+		definition("test_resources/a.json", "a", `{
   "Name": "This is a Test A file"
 }
 `),
-		[]byte(`{
+		definition("test_resources/b.json", "b", `{
   "Name": "This is a Test B file"
 }
+`),
+		definition("test_resources/nested/c.json", "nested/c", `{
+  "Name": "This is a Test nested/C file"
+}
 `),
 	}
 	actualResult, err := loader.ReadDefinitions()
@@ -27,12 +31,12 @@ func TestJsonFilesystemLoader(t *testing.T) {
 func TestJsonFilesystemLoaderWithNames(t *testing.T) {
 	loader := dynamotest.NewJSONFilesystemReader("test_resources/")
 	names := []string{"a", "nested/c"}
-	expectedResult := [][]byte{
-		[]byte(`{
+	expectedResult := []dynamotest.Definition{
+		definition("test_resources/a.json", "a", `{
   "Name": "This is a Test A file"
 }
 `),
-		[]byte(`{
+		definition("test_resources/nested/c.json", "nested/c", `{
   "Name": "This is a Test nested/C file"
 }
 `),
@@ -41,3 +45,76 @@ func TestJsonFilesystemLoaderWithNames(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedResult, actualResult)
 }
+
+func TestJsonFilesystemLoaderWithIncludePatterns(t *testing.T) {
+	loader := dynamotest.NewJSONFilesystemReader("test_resources/")
+	loader.Include = []string{"**/c.json", "b.*"}
+	expectedResult := []dynamotest.Definition{
+		definition("test_resources/b.json", "b", `{
+  "Name": "This is a Test B file"
+}
+`),
+		definition("test_resources/nested/c.json", "nested/c", `{
+  "Name": "This is a Test nested/C file"
+}
+`),
+	}
+	actualResult, err := loader.ReadDefinitions()
+	require.NoError(t, err)
+	require.Equal(t, expectedResult, actualResult)
+}
+
+func TestJsonFilesystemLoaderWithExcludePatterns(t *testing.T) {
+	loader := dynamotest.NewJSONFilesystemReader("test_resources/")
+	loader.Exclude = []string{"nested/**", "a.json"}
+	expectedResult := []dynamotest.Definition{
+		definition("test_resources/b.json", "b", `{
+  "Name": "This is a Test B file"
+}
+`),
+	}
+	actualResult, err := loader.ReadDefinitions()
+	require.NoError(t, err)
+	require.Equal(t, expectedResult, actualResult)
+}
+
+func TestJsonFilesystemLoaderWithInvalidPattern(t *testing.T) {
+	loader := dynamotest.NewJSONFilesystemReader("test_resources/")
+	loader.Include = []string{"[a-"}
+	_, err := loader.ReadDefinitions()
+	require.Error(t, err)
+}
+
+func TestFilesystemLoaderWithMultipleExtensions(t *testing.T) {
+	loader := dynamotest.NewFilesystemDirectoryLoader("test_resources/", "json", "yml")
+	loader.Exclude = []string{"a.*", "nested/**"}
+	expectedResult := []dynamotest.Definition{
+		definition("test_resources/b.json", "b", `{
+  "Name": "This is a Test B file"
+}
+`),
+		definition("test_resources/d.yml", "d", "name: This is a Test D file\n"),
+	}
+	actualResult, err := loader.ReadDefinitions()
+	require.NoError(t, err)
+	require.Equal(t, expectedResult, actualResult)
+}
+
+func TestFilesystemLoaderResolvesNamesWithMultipleExtensions(t *testing.T) {
+	loader := dynamotest.NewFilesystemDirectoryLoader("test_resources/", "json", "yml")
+	actualResult, err := loader.ReadDefinitions("d")
+	require.NoError(t, err)
+	require.Equal(t, []dynamotest.Definition{
+		definition("test_resources/d.yml", "d", "name: This is a Test D file\n"),
+	}, actualResult)
+
+	_, err = loader.ReadDefinitions("missing")
+	require.Error(t, err)
+}
+
+func definition(path, name, contents string) dynamotest.Definition {
+	return dynamotest.Definition{
+		Source:   dynamotest.Source{Path: path, Name: name},
+		Contents: []byte(contents),
+	}
+}
@@ -0,0 +1,219 @@
+package dynamotest
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+// referencePattern matches references to attributes of aliased items, e.g. {{ref alice.userId}}
+var referencePattern = regexp.MustCompile(`\{\{\s*ref\s+([^\s.{}"]+)\.([^\s{}"]+)\s*\}\}`)
+
+// resolveReferences replaces {{ref alias.attribute}} references in string attributes of all operations
+// with values of attributes of items declaring the alias with "$id" attribute, i.e. Alias of their operations.
+// A string consisting of a single reference takes the referenced value with its type, e.g. a number,
+// otherwise referenced strings and numbers are embedded in the string. Referenced attributes may contain
+// references themselves. Errors point at fixtures and positions of items given by origins.
+func resolveReferences(operations TableOperations, origins map[*Operation]itemOrigin) error {
+	r := &referenceResolver{
+		aliases: make(map[string]map[string]*dynamodb.AttributeValue),
+		state:   make(map[string]resolveState),
+	}
+	aliasOrigins := make(map[string]itemOrigin)
+
+	tableNames := make([]string, 0, len(operations))
+	for tableName := range operations {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		for _, operation := range operations[tableName] {
+			alias := operation.Alias
+			if alias == "" {
+				continue
+			}
+			origin := origins[operation]
+			if declared, ok := aliasOrigins[alias]; ok {
+				return errors.Errorf("fixtures: %s: item %d of table '%s': alias '%s' is already declared by item %d of '%s'",
+					origin.Path, origin.Index, tableName, alias, declared.Index, declared.Path)
+			}
+			r.aliases[alias] = operation.Item
+			aliasOrigins[alias] = origin
+		}
+	}
+
+	for _, tableName := range tableNames {
+		for _, operation := range operations[tableName] {
+			var err error
+			if operation.Alias != "" {
+				err = r.resolveAliasedItem(operation.Alias)
+			} else {
+				err = r.resolveItem(operation.attributes())
+			}
+			if err != nil {
+				origin := origins[operation]
+				return errors.Wrapf(err, "fixtures: %s: item %d of table '%s'", origin.Path, origin.Index, tableName)
+			}
+		}
+	}
+
+	return nil
+}
+
+type resolveState int
+
+const (
+	resolving resolveState = iota + 1
+	resolved
+)
+
+type referenceResolver struct {
+	aliases map[string]map[string]*dynamodb.AttributeValue
+	// state of attributes of aliased items, by "alias.attribute", detects circular references
+	state map[string]resolveState
+}
+
+func (r *referenceResolver) resolveItem(item map[string]*dynamodb.AttributeValue) error {
+	for _, name := range sortedAttributeNames(item) {
+		resolvedValue, err := r.resolveValue(item[name])
+		if err != nil {
+			return errors.Wrapf(err, "attribute '%s'", name)
+		}
+		item[name] = resolvedValue
+	}
+
+	return nil
+}
+
+func (r *referenceResolver) resolveAliasedItem(alias string) error {
+	for _, name := range sortedAttributeNames(r.aliases[alias]) {
+		_, err := r.resolveAttribute("", alias, name)
+		if err != nil {
+			return errors.Wrapf(err, "attribute '%s'", name)
+		}
+	}
+
+	return nil
+}
+
+func sortedAttributeNames(item map[string]*dynamodb.AttributeValue) []string {
+	names := make([]string, 0, len(item))
+	for name := range item {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// resolveAttribute returns the attribute of aliased item, resolving its own references first.
+// Reference is empty when the attribute is resolved as a part of its own item.
+func (r *referenceResolver) resolveAttribute(reference, alias, name string) (*dynamodb.AttributeValue, error) {
+	item, ok := r.aliases[alias]
+	if !ok {
+		return nil, errors.Errorf("dangling reference '%s': alias '%s' is not declared", reference, alias)
+	}
+	value, ok := item[name]
+	if !ok {
+		return nil, errors.Errorf("dangling reference '%s': item '%s' has no attribute '%s'", reference, alias, name)
+	}
+
+	key := alias + "." + name
+	switch r.state[key] {
+	case resolved:
+		return value, nil
+	case resolving:
+		return nil, errors.Errorf("circular reference '%s'", reference)
+	}
+
+	r.state[key] = resolving
+	value, err := r.resolveValue(value)
+	if err != nil {
+		if reference != "" {
+			err = errors.Wrapf(err, "reference '%s'", reference)
+		}
+		return nil, err
+	}
+	item[name] = value
+	r.state[key] = resolved
+
+	return value, nil
+}
+
+func (r *referenceResolver) resolveValue(value *dynamodb.AttributeValue) (*dynamodb.AttributeValue, error) {
+	switch {
+	case value == nil:
+		return value, nil
+	case value.S != nil:
+		s := aws.StringValue(value.S)
+		if match := referencePattern.FindStringSubmatch(s); match != nil && match[0] == s {
+			return r.resolveAttribute(s, match[1], match[2])
+		}
+		resolvedString, err := r.resolveString(s)
+		if err != nil {
+			return nil, err
+		}
+		return &dynamodb.AttributeValue{S: aws.String(resolvedString)}, nil
+	case value.SS != nil:
+		ss := make([]*string, len(value.SS))
+		for i, s := range value.SS {
+			resolvedString, err := r.resolveString(aws.StringValue(s))
+			if err != nil {
+				return nil, err
+			}
+			ss[i] = aws.String(resolvedString)
+		}
+		return &dynamodb.AttributeValue{SS: ss}, nil
+	case value.L != nil:
+		l := make([]*dynamodb.AttributeValue, len(value.L))
+		for i, element := range value.L {
+			resolvedElement, err := r.resolveValue(element)
+			if err != nil {
+				return nil, errors.Wrapf(err, "element %d", i)
+			}
+			l[i] = resolvedElement
+		}
+		return &dynamodb.AttributeValue{L: l}, nil
+	case value.M != nil:
+		m := make(map[string]*dynamodb.AttributeValue, len(value.M))
+		for name, element := range value.M {
+			resolvedElement, err := r.resolveValue(element)
+			if err != nil {
+				return nil, errors.Wrapf(err, "attribute '%s'", name)
+			}
+			m[name] = resolvedElement
+		}
+		return &dynamodb.AttributeValue{M: m}, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveString embeds referenced strings and numbers in given string
+func (r *referenceResolver) resolveString(s string) (string, error) {
+	var resolveErr error
+	result := referencePattern.ReplaceAllStringFunc(s, func(reference string) string {
+		if resolveErr != nil {
+			return reference
+		}
+		match := referencePattern.FindStringSubmatch(reference)
+		value, err := r.resolveAttribute(reference, match[1], match[2])
+		switch {
+		case err != nil:
+			resolveErr = err
+		case value.S != nil:
+			return aws.StringValue(value.S)
+		case value.N != nil:
+			return aws.StringValue(value.N)
+		default:
+			resolveErr = errors.Errorf("reference '%s' must be a string or a number to be embedded", reference)
+		}
+		return reference
+	})
+
+	return result, resolveErr
+}
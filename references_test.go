@@ -0,0 +1,106 @@
+package dynamotest_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamoTesterLoadFixturesResolvesReferences(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createFixturesDynamoTester(dynamoSvc, createSampleReferencingFixtures())
+
+	err := tester.LoadFixtures()
+
+	require.NoError(t, err)
+	orders := dynamoSvc.items(tester.TableNameFor("tableName"))
+	require.Len(t, orders, 1)
+	require.Equal(t, "USER#u-1#ORDER", aws.StringValue(orders[0]["PK"].S))
+	require.Equal(t, &dynamodb.AttributeValue{N: aws.String("1")}, orders[0]["owner"])
+	require.Equal(t, "u-1-friend", aws.StringValue(orders[0]["tags"].L[0].S))
+
+	users := dynamoSvc.items(tester.TableNameFor("otherTable"))
+	require.Len(t, users, 2)
+	for _, user := range users {
+		require.NotContains(t, user, "$id")
+	}
+}
+
+func TestDynamoTesterLoadFixturesReportsInvalidReferences(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fixture  string
+		expected string
+	}{
+		{
+			name:     "unknown alias",
+			fixture:  `{"table": "tableName", "items": [{"ID": 1, "owner": "{{ref carol.userId}}"}]}`,
+			expected: "fixtures: fixture.json: item 0 of table 'tableName': attribute 'owner': dangling reference '{{ref carol.userId}}': alias 'carol' is not declared",
+		},
+		{
+			name:     "unknown attribute",
+			fixture:  `{"table": "tableName", "items": [{"$id": "alice", "ID": 1}, {"ID": 2, "owner": "USER#{{ref alice.userId}}"}]}`,
+			expected: "fixtures: fixture.json: item 1 of table 'tableName': attribute 'owner': dangling reference '{{ref alice.userId}}': item 'alice' has no attribute 'userId'",
+		},
+		{
+			name:     "circular reference",
+			fixture:  `{"table": "tableName", "items": [{"$id": "alice", "ID": 1, "a": "{{ref bob.b}}"}, {"$id": "bob", "ID": 2, "b": "{{ref alice.a}}"}]}`,
+			expected: "circular reference '{{ref alice.a}}'",
+		},
+		{
+			name:     "duplicated alias",
+			fixture:  `{"table": "tableName", "items": [{"$id": "alice", "ID": 1}, {"$id": "alice", "ID": 2}]}`,
+			expected: "fixtures: fixture.json: item 1 of table 'tableName': alias 'alice' is already declared by item 0 of 'fixture.json'",
+		},
+		{
+			name:     "non-scalar embedded",
+			fixture:  `{"table": "tableName", "items": [{"$id": "alice", "ID": 1, "tags": ["a"]}, {"ID": 2, "owner": "#{{ref alice.tags}}"}]}`,
+			expected: "reference '{{ref alice.tags}}' must be a string or a number to be embedded",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tester := createFixturesDynamoTester(newFakeDynamoDB(), staticLoader{"fixture": []byte(tc.fixture)})
+
+			err := tester.LoadFixtures()
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestDynamoTesterLoadFixturesReportsInvalidReferencesAtFixture(t *testing.T) {
+	tester := createFixturesDynamoTester(newFakeDynamoDB(), staticLoader{
+		"first":  []byte(`{"table": "tableName", "items": [{"$id": "alice", "ID": 1}]}`),
+		"second": []byte(`{"table": "tableName", "items": [{"ID": 2, "owner": "{{ref alice.userId}}"}]}`),
+	})
+
+	err := tester.LoadFixtures("first", "second")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "fixtures: second.json: item 0 of table 'tableName': attribute 'owner'")
+}
+
+func createSampleReferencingFixtures() staticLoader {
+	return staticLoader{
+		"orders": []byte(`{"table": "tableName", "items": [
+			{"ID": 10, "PK": "USER#{{ref alice.userId}}#ORDER", "owner": "{{ref alice.ID}}", "tags": ["{{ref bob.userId}}"]}
+		]}`),
+		"users": []byte(`{"table": "otherTable", "items": [
+			{"$id": "alice", "ID": 1, "userId": "u-1"},
+			{"$id": "bob", "ID": 2, "userId": "{{ref alice.userId}}-friend", "$format": "json"}
+		]}`),
+	}
+}
+
+func createFixturesDynamoTester(dynamoSvc *fakeDynamoDB, fixtures staticLoader) *dynamotest.DynamoTester {
+	tester := createSampleDynamoTester(dynamoSvc)
+	tester.FixturesLoader = fixtures
+
+	return tester
+}
@@ -3,6 +3,8 @@ package dynamotest
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -67,6 +69,44 @@ func (r *TimestampTableNameResolver) Resolve(tableName string) string {
 	return fmt.Sprintf("%s_%d", tableName, r.clock.Time().UnixNano())
 }
 
+// maxTableNameLen is the maximum length of DynamoDB table name
+const maxTableNameLen = 255
+
+// TestNameTableNameResolver attaches the name of a test and a timestamp to the table name,
+// so every test (and subtest) gets its own set of tables
+type TestNameTableNameResolver struct {
+	testName string
+	clock    Clock
+}
+
+// NewTestNameTableNameResolver creates new instance of TestNameTableNameResolver.
+// Characters not allowed in table names, like slashes separating subtests, are replaced with underscores.
+func NewTestNameTableNameResolver(testName string, clock Clock) *TestNameTableNameResolver {
+	return &TestNameTableNameResolver{testName: sanitizeTableName(testName), clock: clock}
+}
+
+func (r *TestNameTableNameResolver) Resolve(tableName string) string {
+	timestamp := strconv.FormatInt(r.clock.Time().UnixNano(), 10)
+	testName := r.testName
+	if maxLen := maxTableNameLen - len(tableName) - len(timestamp) - 2; len(testName) > maxLen {
+		if maxLen <= 0 {
+			return fmt.Sprintf("%s_%s", tableName, timestamp)
+		}
+		testName = testName[:maxLen]
+	}
+
+	return fmt.Sprintf("%s_%s_%s", tableName, testName, timestamp)
+}
+
+func sanitizeTableName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
 type MemoizedTableNameResolver struct {
 	resolver   TableNameResolver
 	localCache map[string]string
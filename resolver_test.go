@@ -1,6 +1,7 @@
 package dynamotest_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -63,3 +64,23 @@ func TestTimestampBasedTableNameResolver(t *testing.T) {
 
 	require.Equal(t, expectedTableName, actualTableName)
 }
+
+func TestTestNameTableNameResolver(t *testing.T) {
+	frozenTime := time.Date(2019, 4, 5, 12, 55, 13, 1, time.UTC)
+	clock := dynamotest.FakeClock{FrozenTime: frozenTime}
+	resolver := dynamotest.NewTestNameTableNameResolver("TestPets/adopt a pet#01", &clock)
+
+	actualTableName := resolver.Resolve("tableName")
+
+	require.Equal(t, "tableName_TestPets_adopt_a_pet_01_1554468913000000001", actualTableName)
+}
+
+func TestTestNameTableNameResolverTruncatesLongNames(t *testing.T) {
+	clock := dynamotest.FakeClock{FrozenTime: time.Date(2019, 4, 5, 12, 55, 13, 1, time.UTC)}
+	resolver := dynamotest.NewTestNameTableNameResolver(strings.Repeat("TestPets/", 40), &clock)
+
+	actualTableName := resolver.Resolve("tableName")
+
+	require.Len(t, actualTableName, 255)
+	require.True(t, strings.HasSuffix(actualTableName, "_1554468913000000001"))
+}
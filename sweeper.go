@@ -0,0 +1,202 @@
+package dynamotest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+// TableSweeper removes test tables left behind by crashed test runs.
+// It recognizes tables named by TimestampTableNameResolver, TestNameTableNameResolver and RandomTableNameResolver
+// for given base names and deletes the ones older than MaxAge. Age is read from the timestamp suffix,
+// if there is one, or from table's CreationDateTime otherwise. Only exact formats of the resolvers are recognized,
+// so tables like "pets_archive" or "pets_archive_<timestamp>" are kept unless "pets_archive" is a base name itself.
+type TableSweeper struct {
+	dynamoSvc dynamodbiface.DynamoDBAPI
+	BaseNames []string
+	MaxAge    time.Duration
+	// DryRun makes Sweep only report tables it would delete
+	DryRun bool
+	Clock  Clock
+	// RandomSuffixLen is the length of suffixes generated by RandomTableNameResolver.
+	// Random suffixes cannot be told apart from words, e.g. "pets_audit", so tables named by RandomTableNameResolver
+	// are swept only when it's set.
+	RandomSuffixLen int
+}
+
+// NewTableSweeper creates new instance of TableSweeper removing tables derived from base names older than maxAge
+func NewTableSweeper(dynamoSvc dynamodbiface.DynamoDBAPI, maxAge time.Duration, baseNames ...string) *TableSweeper {
+	return &TableSweeper{
+		dynamoSvc: dynamoSvc,
+		BaseNames: baseNames,
+		MaxAge:    maxAge,
+		Clock:     new(RealClock),
+	}
+}
+
+// SweptTable describes a table deleted by TableSweeper, or one that would be deleted in dry-run mode
+type SweptTable struct {
+	Name      string
+	BaseName  string
+	CreatedAt time.Time
+}
+
+// Sweep deletes orphaned tables and returns the list of them.
+// It doesn't stop on the first failed deletion, all of them are returned as SweepError.
+func (s *TableSweeper) Sweep() ([]SweptTable, error) {
+	tableNames, err := s.listTables()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []SweptTable
+	var errs []error
+	now := s.Clock.Time()
+	for _, tableName := range tableNames {
+		baseName, createdAt, ok, err := s.inspectTable(tableName)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok || now.Sub(createdAt) <= s.MaxAge {
+			continue
+		}
+
+		if !s.DryRun {
+			_, err = s.dynamoSvc.DeleteTable(&dynamodb.DeleteTableInput{TableName: aws.String(tableName)})
+			if err != nil && !isAWSErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+				errs = append(errs, errors.Wrapf(err, "sweep: cannot delete table '%s'", tableName))
+				continue
+			}
+		}
+
+		result = append(result, SweptTable{Name: tableName, BaseName: baseName, CreatedAt: createdAt})
+	}
+
+	if len(errs) > 0 {
+		return result, &SweepError{Errors: errs}
+	}
+
+	return result, nil
+}
+
+func (s *TableSweeper) listTables() ([]string, error) {
+	var result []string
+	input := &dynamodb.ListTablesInput{}
+	for {
+		output, err := s.dynamoSvc.ListTables(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "sweep: cannot list tables")
+		}
+
+		result = append(result, aws.StringValueSlice(output.TableNames)...)
+		if output.LastEvaluatedTableName == nil {
+			return result, nil
+		}
+		input.ExclusiveStartTableName = output.LastEvaluatedTableName
+	}
+}
+
+// inspectTable tells whether the table has been created for one of base names and when it was created
+func (s *TableSweeper) inspectTable(tableName string) (string, time.Time, bool, error) {
+	baseName, suffix, ok := s.matchBaseName(tableName)
+	if !ok {
+		return "", time.Time{}, false, nil
+	}
+
+	if createdAt, ok := timestampFromSuffix(suffix); ok {
+		return baseName, createdAt, true, nil
+	}
+
+	if !s.isRandomSuffix(suffix) {
+		return "", time.Time{}, false, nil
+	}
+
+	output, err := s.dynamoSvc.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		if isAWSErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+			return "", time.Time{}, false, nil
+		}
+		return "", time.Time{}, false, errors.Wrapf(err, "sweep: cannot describe table '%s'", tableName)
+	}
+	if output.Table.CreationDateTime == nil {
+		return "", time.Time{}, false, nil
+	}
+
+	return baseName, *output.Table.CreationDateTime, true, nil
+}
+
+// matchBaseName finds the longest base name the table name has been derived from
+func (s *TableSweeper) matchBaseName(tableName string) (string, string, bool) {
+	baseNames := make([]string, len(s.BaseNames))
+	copy(baseNames, s.BaseNames)
+	sort.Slice(baseNames, func(i, j int) bool {
+		return len(baseNames[i]) > len(baseNames[j])
+	})
+
+	for _, baseName := range baseNames {
+		prefix := baseName + "_"
+		if strings.HasPrefix(tableName, prefix) && len(tableName) > len(prefix) {
+			return baseName, tableName[len(prefix):], true
+		}
+	}
+
+	return "", "", false
+}
+
+// timestampLen is the number of digits of nanosecond timestamps appended to table names.
+// Shorter numbers are not treated as timestamps, so tables like "pets_2" are never swept by accident.
+const timestampLen = 19
+
+// testNameSuffixPattern matches suffixes generated by TestNameTableNameResolver for names of tests, benchmarks,
+// fuzz tests and examples, e.g. "TestPets_case_<timestamp>"
+var testNameSuffixPattern = regexp.MustCompile(`^(?:Test|Benchmark|Fuzz|Example)[a-zA-Z0-9_.-]*_([0-9]+)$`)
+
+// timestampFromSuffix reads the time from suffixes generated by TimestampTableNameResolver ("<timestamp>")
+// and TestNameTableNameResolver ("<test name>_<timestamp>")
+func timestampFromSuffix(suffix string) (time.Time, bool) {
+	if match := testNameSuffixPattern.FindStringSubmatch(suffix); match != nil {
+		suffix = match[1]
+	}
+	if len(suffix) != timestampLen {
+		return time.Time{}, false
+	}
+
+	nanos, err := strconv.ParseInt(suffix, 10, 64)
+	if err != nil || nanos < 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, nanos), true
+}
+
+func (s *TableSweeper) isRandomSuffix(suffix string) bool {
+	if s.RandomSuffixLen == 0 || len(suffix) != s.RandomSuffixLen {
+		return false
+	}
+
+	for _, c := range suffix {
+		if !strings.ContainsRune(letterBytes, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SweepError collects errors of all tables that couldn't be inspected or deleted by TableSweeper
+type SweepError struct {
+	Errors []error
+}
+
+func (e *SweepError) Error() string {
+	return fmt.Sprintf("sweep: %d table(s) not swept: %s", len(e.Errors), joinErrors(e.Errors))
+}
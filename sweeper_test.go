@@ -0,0 +1,113 @@
+package dynamotest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableSweeper(t *testing.T) {
+	dynamoSvc := createSampleSweptDynamoDB()
+	sweeper := dynamotest.NewTableSweeper(dynamoSvc, time.Hour, "pets", "pets_archive")
+	sweeper.Clock = dynamotest.FakeClock{FrozenTime: time.Date(2019, 4, 5, 12, 0, 0, 0, time.UTC)}
+	sweeper.RandomSuffixLen = 5
+
+	swept, err := sweeper.Sweep()
+
+	require.NoError(t, err)
+	require.Equal(t, []dynamotest.SweptTable{
+		{
+			Name:      "pets_1554454800000000000",
+			BaseName:  "pets",
+			CreatedAt: time.Unix(0, 1554454800000000000),
+		},
+		{
+			Name:      "pets_TestPets_case_1554454800000000000",
+			BaseName:  "pets",
+			CreatedAt: time.Unix(0, 1554454800000000000),
+		},
+		{
+			Name:      "pets_abcde",
+			BaseName:  "pets",
+			CreatedAt: time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:      "pets_archive_1554454800000000000",
+			BaseName:  "pets_archive",
+			CreatedAt: time.Unix(0, 1554454800000000000),
+		},
+	}, swept)
+	require.Equal(t, []string{
+		"owners_1554454800000000000",
+		"pets",
+		"pets_1554465600000000000",
+		"pets_abc",
+		"pets_fghij",
+	}, dynamoSvc.tableNames())
+}
+
+func TestTableSweeperDryRun(t *testing.T) {
+	dynamoSvc := createSampleSweptDynamoDB()
+	tablesBefore := dynamoSvc.tableNames()
+	sweeper := dynamotest.NewTableSweeper(dynamoSvc, time.Hour, "pets")
+	sweeper.Clock = dynamotest.FakeClock{FrozenTime: time.Date(2019, 4, 5, 12, 0, 0, 0, time.UTC)}
+	sweeper.DryRun = true
+
+	swept, err := sweeper.Sweep()
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"pets_1554454800000000000", "pets_TestPets_case_1554454800000000000"}, sweptNames(swept))
+	require.Equal(t, tablesBefore, dynamoSvc.tableNames())
+}
+
+func TestTableSweeperKeepsRandomlyNamedTablesByDefault(t *testing.T) {
+	dynamoSvc := createSampleSweptDynamoDB()
+	input := createSampleCreateTableInput()
+	input.TableName = aws.String("pets_audit")
+	_, _ = dynamoSvc.CreateTable(input)
+	dynamoSvc.tables["pets_audit"].description.CreationDateTime = aws.Time(time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC))
+	sweeper := dynamotest.NewTableSweeper(dynamoSvc, time.Hour, "pets")
+	sweeper.Clock = dynamotest.FakeClock{FrozenTime: time.Date(2019, 4, 5, 12, 0, 0, 0, time.UTC)}
+
+	swept, err := sweeper.Sweep()
+
+	require.NoError(t, err)
+	require.NotContains(t, sweptNames(swept), "pets_audit")
+	require.NotContains(t, sweptNames(swept), "pets_abcde")
+	require.Contains(t, dynamoSvc.tableNames(), "pets_archive_1554454800000000000")
+}
+
+func sweptNames(swept []dynamotest.SweptTable) []string {
+	names := make([]string, 0, len(swept))
+	for _, table := range swept {
+		names = append(names, table.Name)
+	}
+
+	return names
+}
+
+func createSampleSweptDynamoDB() *fakeDynamoDB {
+	dynamoSvc := newFakeDynamoDB()
+	creationTimes := map[string]time.Time{
+		"pets":                                   time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC),
+		"pets_1554454800000000000":               time.Date(2019, 4, 5, 12, 0, 0, 0, time.UTC),
+		"pets_1554465600000000000":               time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC),
+		"pets_TestPets_case_1554454800000000000": time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC),
+		"pets_abcde":                             time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC),
+		"pets_fghij":                             time.Date(2019, 4, 5, 11, 30, 0, 0, time.UTC),
+		"pets_abc":                               time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC),
+		"pets_archive_1554454800000000000":       time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC),
+		"owners_1554454800000000000":             time.Date(2019, 4, 5, 10, 0, 0, 0, time.UTC),
+	}
+	for tableName, createdAt := range creationTimes {
+		input := createSampleCreateTableInput()
+		input.TableName = aws.String(tableName)
+		_, _ = dynamoSvc.CreateTable(input)
+		dynamoSvc.tables[tableName].description.CreationDateTime = aws.Time(createdAt)
+	}
+
+	return dynamoSvc
+}
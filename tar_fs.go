@@ -0,0 +1,195 @@
+package dynamotest
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// tarFS is a read-only fs.FS holding regular files of a tar archive in memory.
+// Directories are not stored in archives consistently, so they are derived from paths of files.
+type tarFS struct {
+	files map[string]*tarEntry
+	// dirs maps slash-separated paths of directories ("." for the root) to their sorted entries
+	dirs map[string][]*tarEntry
+}
+
+func readTarFS(r io.Reader, archivePath string) (*tarFS, error) {
+	fsys := &tarFS{files: make(map[string]*tarEntry), dirs: map[string][]*tarEntry{".": nil}}
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrate: cannot read tar archive: '%s'", archivePath)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		contents, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrate: cannot read '%s' from tar archive: '%s'", header.Name, archivePath)
+		}
+
+		name := strings.TrimPrefix(path.Clean(strings.TrimPrefix(header.Name, "/")), "./")
+		if !fs.ValidPath(name) || name == "." {
+			return nil, errors.Errorf("migrate: invalid path '%s' in tar archive: '%s'", header.Name, archivePath)
+		}
+		fsys.addFile(name, &tarEntry{
+			name:     path.Base(name),
+			mode:     fs.FileMode(header.Mode).Perm(),
+			modTime:  header.ModTime,
+			contents: contents,
+		})
+	}
+
+	for _, entries := range fsys.dirs {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].name < entries[j].name
+		})
+	}
+
+	return fsys, nil
+}
+
+// addFile stores the file and adds it to entries of its directory, creating missing parent directories
+func (f *tarFS) addFile(name string, file *tarEntry) {
+	f.addDir(path.Dir(name))
+	if _, ok := f.files[name]; !ok {
+		f.dirs[path.Dir(name)] = append(f.dirs[path.Dir(name)], file)
+	}
+	f.files[name] = file
+}
+
+func (f *tarFS) addDir(name string) {
+	if _, ok := f.dirs[name]; ok {
+		return
+	}
+
+	f.addDir(path.Dir(name))
+	f.dirs[name] = nil
+	f.dirs[path.Dir(name)] = append(f.dirs[path.Dir(name)], &tarEntry{name: path.Base(name), mode: fs.ModeDir | 0555})
+}
+
+func (f *tarFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if file, ok := f.files[name]; ok {
+		return &tarFile{tarEntry: file, reader: bytes.NewReader(file.contents)}, nil
+	}
+	if entries, ok := f.dirs[name]; ok {
+		return &tarDir{tarEntry: &tarEntry{name: path.Base(name), mode: fs.ModeDir | 0555}, entries: entries}, nil
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (f *tarFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries, ok := f.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return dirEntries(entries), nil
+}
+
+func (f *tarFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	file, ok := f.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]byte(nil), file.contents...), nil
+}
+
+// tarEntry is a file or a directory of tarFS, it serves as both fs.FileInfo and fs.DirEntry
+type tarEntry struct {
+	name     string
+	mode     fs.FileMode
+	modTime  time.Time
+	contents []byte
+}
+
+func (e *tarEntry) Name() string               { return e.name }
+func (e *tarEntry) Size() int64                { return int64(len(e.contents)) }
+func (e *tarEntry) Mode() fs.FileMode          { return e.mode }
+func (e *tarEntry) ModTime() time.Time         { return e.modTime }
+func (e *tarEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *tarEntry) Sys() interface{}           { return nil }
+func (e *tarEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *tarEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+type tarFile struct {
+	*tarEntry
+	reader *bytes.Reader
+}
+
+func (f *tarFile) Stat() (fs.FileInfo, error) { return f.tarEntry, nil }
+func (f *tarFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+func (f *tarFile) Close() error               { return nil }
+
+func (f *tarFile) Seek(offset int64, whence int) (int64, error) {
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *tarFile) ReadAt(b []byte, offset int64) (int, error) {
+	return f.reader.ReadAt(b, offset)
+}
+
+type tarDir struct {
+	*tarEntry
+	entries []*tarEntry
+	offset  int
+}
+
+func (d *tarDir) Stat() (fs.FileInfo, error) { return d.tarEntry, nil }
+func (d *tarDir) Close() error               { return nil }
+
+func (d *tarDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *tarDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if count > 0 {
+		if len(remaining) == 0 {
+			return nil, io.EOF
+		}
+		if count < len(remaining) {
+			remaining = remaining[:count]
+		}
+	}
+	d.offset += len(remaining)
+
+	return dirEntries(remaining), nil
+}
+
+func dirEntries(entries []*tarEntry) []fs.DirEntry {
+	result := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+
+	return result
+}
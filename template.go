@@ -0,0 +1,208 @@
+package dynamotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SeedEnv is the environment variable overriding the default seed of TemplateFixturesDecoder,
+// so a failing run can be replayed with the seed it has logged
+const SeedEnv = "DYNAMOTEST_SEED"
+
+// TemplateFixturesDecoder executes fixtures as text/template templates before decoding them with Decoder.
+// Besides the actions of text/template, e.g. {{ range }} loops, templates can use the following functions:
+//
+//	now                   current time of Clock
+//	addDays N TIME        TIME moved by N days, e.g. {{ now | addDays -3 }}
+//	rfc3339 TIME          TIME formatted as RFC 3339, e.g. {{ now | addDays -3 | rfc3339 }}
+//	unix TIME             TIME as Unix timestamp in seconds
+//	uuid                  random version 4 UUID
+//	randInt MIN MAX       random integer in [MIN, MAX)
+//	seq NAME              next number of the named sequence, starting with 1
+//	env NAME [DEFAULT]    value of the environment variable, or DEFAULT when it's empty
+//	times N               numbers from 0 to N-1, e.g. {{ range $i := times 3 }}
+//	json VALUE            VALUE as JSON literal, with quotes and escapes, e.g. "region": {{ env "AWS_REGION" | json }}
+//
+// Functions returning strings are not escaped, so values that may contain quotes, backslashes or new lines,
+// e.g. of env, should be embedded with json instead of being put between quotes. JSON literals are valid YAML as well.
+//
+// References to aliased items, e.g. {{ref alice.userId}}, are passed through to be resolved by LoadFixtures.
+//
+// Random values are derived from Seed and sequences keep counting across Decode calls,
+// so with FakeClock and the same Seed every run produces exactly the same fixtures.
+type TemplateFixturesDecoder struct {
+	Decoder FixturesDecoder
+	Clock   Clock
+	Seed    int64
+	// Funcs are additional functions available in templates, overriding built-in ones
+	Funcs template.FuncMap
+
+	mutex     sync.Mutex
+	random    *rand.Rand
+	sequences map[string]int
+}
+
+// NewTemplateFixturesDecoder creates TemplateFixturesDecoder executing templates before decoding them with decoder.
+// The seed is read from DYNAMOTEST_SEED environment variable or, if it's not set, from the current time.
+func NewTemplateFixturesDecoder(decoder FixturesDecoder, clock Clock) *TemplateFixturesDecoder {
+	return &TemplateFixturesDecoder{Decoder: decoder, Clock: clock, Seed: defaultSeed()}
+}
+
+func defaultSeed() int64 {
+	if seed, err := strconv.ParseInt(os.Getenv(SeedEnv), 10, 64); err == nil {
+		return seed
+	}
+
+	return time.Now().UnixNano()
+}
+
+func (d *TemplateFixturesDecoder) Decode(input []Definition) (TableWriteRequests, error) {
+	executed, err := d.renderAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Decoder.Decode(executed)
+}
+
+func (d *TemplateFixturesDecoder) DecodeOperations(input []Definition) (TableOperations, error) {
+	executed, err := d.renderAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeOperations(d.Decoder, executed)
+}
+
+func (d *TemplateFixturesDecoder) renderAll(input []Definition) ([]Definition, error) {
+	executed := make([]Definition, 0, len(input))
+	for _, fixtureDefinition := range input {
+		rendered, err := d.Render(fixtureDefinition)
+		if err != nil {
+			return nil, err
+		}
+		executed = append(executed, rendered)
+	}
+
+	return executed, nil
+}
+
+// Render executes the template of the fixture, without decoding it. DynamoTester renders fixtures
+// to read their include and extends before decoding them with Decoder.
+func (d *TemplateFixturesDecoder) Render(input Definition) (Definition, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.random == nil {
+		d.random = rand.New(rand.NewSource(d.Seed)) // nolint:gosec
+		d.sequences = make(map[string]int)
+	}
+
+	contents, err := d.execute(input)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	return Definition{Source: input.Source, Contents: contents}, nil
+}
+
+var templateErrorLine = regexp.MustCompile(`^template: .*?:(\d+)(:\d+)?:`)
+
+func (d *TemplateFixturesDecoder) execute(input Definition) ([]byte, error) {
+	contents := referencePattern.ReplaceAllStringFunc(string(input.Contents), func(reference string) string {
+		return "{{" + strconv.Quote(reference) + "}}"
+	})
+	tmpl, err := template.New(input.Path).
+		Option("missingkey=error").
+		Funcs(d.funcs()).
+		Funcs(d.Funcs).
+		Parse(contents)
+	if err == nil {
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, nil)
+		if err == nil {
+			return buf.Bytes(), nil
+		}
+	}
+
+	decodeErr := &DecodeError{Source: input.Source, Err: errors.Wrap(err, "cannot execute template")}
+	if match := templateErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		decodeErr.Line, _ = strconv.Atoi(match[1])
+	}
+
+	return nil, decodeErr
+}
+
+func (d *TemplateFixturesDecoder) funcs() template.FuncMap {
+	return template.FuncMap{
+		"now": func() time.Time {
+			return d.Clock.Time()
+		},
+		"addDays": func(days int, t time.Time) time.Time {
+			return t.AddDate(0, 0, days)
+		},
+		"rfc3339": func(t time.Time) string {
+			return t.Format(time.RFC3339)
+		},
+		"unix": func(t time.Time) int64 {
+			return t.Unix()
+		},
+		"uuid": d.uuid,
+		"randInt": func(min, max int) (int, error) {
+			if max <= min {
+				return 0, errors.Errorf("randInt: max must be greater than min, got %d and %d", min, max)
+			}
+			return min + d.random.Intn(max-min), nil
+		},
+		"seq": func(name string) int {
+			d.sequences[name]++
+			return d.sequences[name]
+		},
+		"env": func(name string, defaultValue ...string) string {
+			if value := os.Getenv(name); value != "" || len(defaultValue) == 0 {
+				return value
+			}
+			return defaultValue[0]
+		},
+		"json": toJSON,
+		"times": func(n int) []int {
+			result := make([]int, n)
+			for i := range result {
+				result[i] = i
+			}
+			return result
+		},
+	}
+}
+
+// toJSON marshals the value as JSON literal, leaving characters like "<" and "&" unescaped
+func toJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", errors.Wrap(err, "json")
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func (d *TemplateFixturesDecoder) uuid() string {
+	var b [16]byte
+	d.random.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
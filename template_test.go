@@ -0,0 +1,164 @@
+package dynamotest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFixturesDecoder(t *testing.T) {
+	decoder := newSampleTemplateDecoder(42)
+
+	actual, err := decoder.Decode([]dynamotest.Definition{createSampleTemplateFixture()})
+
+	require.NoError(t, err)
+	require.Len(t, actual["tableName"], 2)
+	for i, writeRequest := range actual["tableName"] {
+		item := writeRequest.PutRequest.Item
+		require.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, aws.StringValue(item["ID"].S))
+		require.Equal(t, &dynamodb.AttributeValue{N: aws.String([]string{"1", "2"}[i])}, item["Number"])
+		require.Equal(t, "2019-04-02T12:00:00Z", aws.StringValue(item["CreatedAt"].S))
+		require.Equal(t, "local", aws.StringValue(item["Region"].S))
+	}
+	require.NotEqual(t, actual["tableName"][0].PutRequest.Item["ID"], actual["tableName"][1].PutRequest.Item["ID"])
+}
+
+func TestTemplateFixturesDecoderIsReproducibleWithSameSeed(t *testing.T) {
+	input := []dynamotest.Definition{createSampleTemplateFixture()}
+
+	first, err := newSampleTemplateDecoder(42).Decode(input)
+	require.NoError(t, err)
+	second, err := newSampleTemplateDecoder(42).Decode(input)
+	require.NoError(t, err)
+	other, err := newSampleTemplateDecoder(43).Decode(input)
+	require.NoError(t, err)
+
+	require.Equal(t, first, second)
+	require.NotEqual(t, first, other)
+}
+
+func TestTemplateFixturesDecoderKeepsSequencesAcrossCalls(t *testing.T) {
+	decoder := newSampleTemplateDecoder(42)
+	input := []dynamotest.Definition{createSampleTemplateFixture()}
+
+	first, err := decoder.Decode(input)
+	require.NoError(t, err)
+	second, err := decoder.Decode(input)
+	require.NoError(t, err)
+
+	require.Equal(t, "3", aws.StringValue(second["tableName"][0].PutRequest.Item["Number"].N))
+	require.NotEqual(t, first["tableName"][0].PutRequest.Item["ID"], second["tableName"][0].PutRequest.Item["ID"])
+}
+
+func TestTemplateFixturesDecoderReadsEnvironment(t *testing.T) {
+	t.Setenv("DYNAMOTEST_REGION", "eu-west-1")
+	decoder := newSampleTemplateDecoder(42)
+
+	actual, err := decoder.Decode([]dynamotest.Definition{createSampleTemplateFixture()})
+
+	require.NoError(t, err)
+	require.Equal(t, "eu-west-1", aws.StringValue(actual["tableName"][0].PutRequest.Item["Region"].S))
+}
+
+func TestTemplateFixturesDecoderEscapesValuesWithJSON(t *testing.T) {
+	value := "say \"hi\"\n\\o/ <&>"
+	t.Setenv("DYNAMOTEST_GREETING", value)
+	decoders := map[string]struct {
+		decoder  dynamotest.FixturesDecoder
+		contents string
+	}{
+		"json": {dynamotest.NewJSONFixturesDecoder(), `{"table": "tableName", "items": [{"ID": 1, "Greeting": {{ env "DYNAMOTEST_GREETING" | json }}}]}`},
+		"yaml": {dynamotest.NewYAMLFixturesDecoder(), "table: tableName\nitems:\n  - ID: 1\n    Greeting: {{ env \"DYNAMOTEST_GREETING\" | json }}\n"},
+	}
+
+	for name, tc := range decoders {
+		t.Run(name, func(t *testing.T) {
+			decoder := newSampleTemplateDecoder(42)
+			decoder.Decoder = tc.decoder
+
+			actual, err := decoder.Decode([]dynamotest.Definition{definition("fixtures/greetings."+name, "greetings", tc.contents)})
+
+			require.NoError(t, err)
+			require.Equal(t, value, aws.StringValue(actual["tableName"][0].PutRequest.Item["Greeting"].S))
+		})
+	}
+}
+
+func TestTemplateFixturesDecoderInvalidTemplate(t *testing.T) {
+	decoder := newSampleTemplateDecoder(42)
+	input := definition("fixtures/orders.json", "orders", "{\n  \"table\": \"{{ unknown }}\"\n}")
+
+	_, err := decoder.Decode([]dynamotest.Definition{input})
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, input.Source, decodeErr.Source)
+	require.Equal(t, 2, decodeErr.Line)
+	require.Contains(t, err.Error(), "fixtures/orders.json:2: cannot execute template")
+}
+
+func TestTemplateFixturesDecoderUsesSeedFromEnvironment(t *testing.T) {
+	t.Setenv(dynamotest.SeedEnv, "1234")
+
+	decoder := dynamotest.NewTemplateFixturesDecoder(dynamotest.NewJSONFixturesDecoder(), new(dynamotest.RealClock))
+
+	require.Equal(t, int64(1234), decoder.Seed)
+}
+
+func TestNewDynamoTesterWithTemplatedFixtures(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	clock := dynamotest.FakeClock{FrozenTime: time.Date(2019, 4, 5, 12, 0, 0, 0, time.UTC)}
+	fixture := []byte(`{"table": "tableName", "items": [{{ range $i := times 2 }}{{ if $i }},{{ end }}{"ID": {{ seq "order" }}, "OrderID": "{{ uuid }}"}{{ end }}]}`)
+
+	tester, err := dynamotest.NewDynamoTester(
+		dynamoSvc,
+		dynamotest.WithMigrationsLoader(staticLoader{"tableName": createSampleMigrationBytes()}),
+		dynamotest.WithFixturesLoader(staticLoader{"orders": fixture}),
+		dynamotest.WithWaiter(createFastWaiter(dynamoSvc)),
+		dynamotest.WithTemplateClock(clock),
+		dynamotest.WithSeed(42),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, tester.LoadFixtures())
+	decoder, ok := tester.FixturesDecoder.(*dynamotest.TemplateFixturesDecoder)
+	require.True(t, ok)
+	require.Equal(t, int64(42), decoder.Seed)
+	require.NotEqual(t, "tableName_1554465600000000000", tester.TableNameFor("tableName"))
+	require.Equal(t, "2019-04-05T12:00:00Z", decoder.Clock.Time().Format(time.RFC3339))
+	require.Len(t, dynamoSvc.items(tester.TableNameFor("tableName")), 2)
+}
+
+func TestTemplateFixturesDecoderPassesReferencesThrough(t *testing.T) {
+	decoder := newSampleTemplateDecoder(42)
+	input := definition("fixtures/orders.json", "orders", `{"table": "tableName", "items": [{"ID": {{ seq "order" }}, "PK": "USER#{{ref alice.userId}}"}]}`)
+
+	actual, err := decoder.Decode([]dynamotest.Definition{input})
+
+	require.NoError(t, err)
+	require.Equal(t, "USER#{{ref alice.userId}}", aws.StringValue(actual["tableName"][0].PutRequest.Item["PK"].S))
+}
+
+func createSampleTemplateFixture() dynamotest.Definition {
+	return definition("fixtures/orders.json", "orders", `{
+  "table": "tableName",
+  "items": [
+    {{- range $i := times 2 }}{{ if $i }},{{ end }}
+    {"ID": "{{ uuid }}", "Number": {{ seq "order" }}, "CreatedAt": "{{ now | addDays -3 | rfc3339 }}", "Region": "{{ env "DYNAMOTEST_REGION" "local" }}"}
+    {{- end }}
+  ]
+}`)
+}
+
+func newSampleTemplateDecoder(seed int64) *dynamotest.TemplateFixturesDecoder {
+	clock := dynamotest.FakeClock{FrozenTime: time.Date(2019, 4, 5, 12, 0, 0, 0, time.UTC)}
+	decoder := dynamotest.NewTemplateFixturesDecoder(dynamotest.NewJSONFixturesDecoder(), clock)
+	decoder.Seed = seed
+
+	return decoder
+}
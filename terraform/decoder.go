@@ -0,0 +1,312 @@
+// Package terraform reads dynamotest migrations from Terraform files.
+// It's a separate module, so HCL libraries are not dependencies of dynamotest itself.
+package terraform
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+const terraformTableType = "aws_dynamodb_table"
+
+// MigrationDecoder reads tables definitions from Terraform files,
+// so migrations don't drift from the infrastructure code.
+// Every resource "aws_dynamodb_table" block becomes a dynamotest.TableDefinition named after its name attribute.
+//
+// Attributes may be literals or expressions referring to variables ("var.name"),
+// which are resolved from Variables, falling back to defaults of variable blocks of the same file.
+type MigrationDecoder struct {
+	// Variables are values of Terraform variables, e.g. "environment"
+	Variables map[string]string
+}
+
+// NewMigrationDecoder creates MigrationDecoder resolving "var." references with given variables
+func NewMigrationDecoder(variables map[string]string) *MigrationDecoder {
+	return &MigrationDecoder{Variables: variables}
+}
+
+func (d *MigrationDecoder) Decode(input dynamotest.Definition) ([]*dynamotest.TableDefinition, error) {
+	file, diags := hclsyntax.ParseConfig(input.Contents, input.Path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, newHCLDecodeError(input, diags, "cannot parse terraform file")
+	}
+	body := file.Body.(*hclsyntax.Body)
+
+	variables, err := d.collectVariables(input, body)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(variables)},
+	}
+
+	var result []*dynamotest.TableDefinition
+	for _, block := range body.Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 || block.Labels[0] != terraformTableType {
+			continue
+		}
+
+		table, diags := decodeTerraformTable(&terraformBlock{body: block.Body, ctx: ctx, diags: new(hcl.Diagnostics)})
+		if diags.HasErrors() {
+			return nil, newHCLDecodeError(input, diags, "resource '"+terraformTableType+"."+block.Labels[1]+"'")
+		}
+		result = append(result, table)
+	}
+
+	return result, nil
+}
+
+// collectVariables merges defaults of variable blocks with given variables
+func (d *MigrationDecoder) collectVariables(input dynamotest.Definition, body *hclsyntax.Body) (map[string]cty.Value, error) {
+	variables := make(map[string]cty.Value)
+	for _, block := range body.Blocks {
+		if block.Type != "variable" || len(block.Labels) != 1 {
+			continue
+		}
+		defaultValue, ok := block.Body.Attributes["default"]
+		if !ok {
+			continue
+		}
+
+		value, diags := defaultValue.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, newHCLDecodeError(input, diags, "variable '"+block.Labels[0]+"'")
+		}
+		variables[block.Labels[0]] = value
+	}
+
+	for name, value := range d.Variables {
+		variables[name] = cty.StringVal(value)
+	}
+
+	return variables, nil
+}
+
+func decodeTerraformTable(b *terraformBlock) (*dynamotest.TableDefinition, hcl.Diagnostics) {
+	input := &dynamodb.CreateTableInput{
+		TableName: b.string("name"),
+		KeySchema: keySchema(b.string("hash_key"), b.string("range_key")),
+	}
+
+	for _, attribute := range b.blocks("attribute") {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{
+			AttributeName: attribute.string("name"),
+			AttributeType: attribute.string("type"),
+		})
+	}
+
+	billingMode := b.string("billing_mode")
+	payPerRequest := aws.StringValue(billingMode) == dynamodb.BillingModePayPerRequest
+	input.BillingMode = billingMode
+	if !payPerRequest {
+		input.ProvisionedThroughput = b.throughput()
+	}
+
+	for _, index := range b.blocks("global_secondary_index") {
+		gsi := &dynamodb.GlobalSecondaryIndex{
+			IndexName:  index.string("name"),
+			KeySchema:  keySchema(index.string("hash_key"), index.string("range_key")),
+			Projection: index.projection(),
+		}
+		if !payPerRequest {
+			gsi.ProvisionedThroughput = index.throughput()
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+	for _, index := range b.blocks("local_secondary_index") {
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndex{
+			IndexName:  index.string("name"),
+			KeySchema:  keySchema(b.string("hash_key"), index.string("range_key")),
+			Projection: index.projection(),
+		})
+	}
+
+	if aws.BoolValue(b.bool("stream_enabled")) {
+		input.StreamSpecification = &dynamodb.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: b.string("stream_view_type"),
+		}
+	}
+	for _, sse := range b.blocks("server_side_encryption") {
+		input.SSESpecification = &dynamodb.SSESpecification{Enabled: sse.bool("enabled")}
+		if kmsKey := sse.string("kms_key_arn"); kmsKey != nil {
+			input.SSESpecification.SSEType = aws.String(dynamodb.SSETypeKms)
+			input.SSESpecification.KMSMasterKeyId = kmsKey
+		}
+	}
+
+	tags := b.stringMap("tags")
+	tagKeys := make([]string, 0, len(tags))
+	for key := range tags {
+		tagKeys = append(tagKeys, key)
+	}
+	sort.Strings(tagKeys)
+	for _, key := range tagKeys {
+		input.Tags = append(input.Tags, &dynamodb.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	table := &dynamotest.TableDefinition{CreateTableInput: input}
+	for _, ttl := range b.blocks("ttl") {
+		// like in the Terraform AWS provider, TTL is disabled unless enabled explicitly
+		enabled := ttl.bool("enabled")
+		if enabled == nil {
+			enabled = aws.Bool(false)
+		}
+		table.TimeToLiveSpecification = &dynamodb.TimeToLiveSpecification{
+			AttributeName: ttl.string("attribute_name"),
+			Enabled:       enabled,
+		}
+	}
+
+	return table, *b.diags
+}
+
+func keySchema(hashKey, rangeKey *string) []*dynamodb.KeySchemaElement {
+	result := []*dynamodb.KeySchemaElement{
+		{AttributeName: hashKey, KeyType: aws.String(dynamodb.KeyTypeHash)},
+	}
+	if rangeKey != nil {
+		result = append(result, &dynamodb.KeySchemaElement{AttributeName: rangeKey, KeyType: aws.String(dynamodb.KeyTypeRange)})
+	}
+
+	return result
+}
+
+// terraformBlock evaluates attributes of a block, collecting diagnostics of all of them.
+// Nested blocks share diagnostics with their parent.
+type terraformBlock struct {
+	body  *hclsyntax.Body
+	ctx   *hcl.EvalContext
+	diags *hcl.Diagnostics
+}
+
+func (b *terraformBlock) blocks(blockType string) []*terraformBlock {
+	var result []*terraformBlock
+	for _, block := range b.body.Blocks {
+		if block.Type == blockType {
+			result = append(result, &terraformBlock{body: block.Body, ctx: b.ctx, diags: b.diags})
+		}
+	}
+
+	return result
+}
+
+// value evaluates the attribute converted to given type, it returns false when the attribute is missing or null
+func (b *terraformBlock) value(name string, ty cty.Type, target interface{}) bool {
+	attribute, ok := b.body.Attributes[name]
+	if !ok {
+		return false
+	}
+
+	value, diags := attribute.Expr.Value(b.ctx)
+	*b.diags = append(*b.diags, diags...)
+	if diags.HasErrors() || value.IsNull() {
+		return false
+	}
+
+	value, err := convert.Convert(value, ty)
+	if err == nil {
+		err = gocty.FromCtyValue(value, target)
+	}
+	if err != nil {
+		*b.diags = append(*b.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value of " + name,
+			Detail:   err.Error(),
+			Subject:  attribute.Expr.Range().Ptr(),
+		})
+		return false
+	}
+
+	return true
+}
+
+func (b *terraformBlock) string(name string) *string {
+	var result string
+	if !b.value(name, cty.String, &result) {
+		return nil
+	}
+
+	return aws.String(result)
+}
+
+func (b *terraformBlock) int64(name string) *int64 {
+	var result int64
+	if !b.value(name, cty.Number, &result) {
+		return nil
+	}
+
+	return aws.Int64(result)
+}
+
+func (b *terraformBlock) bool(name string) *bool {
+	var result bool
+	if !b.value(name, cty.Bool, &result) {
+		return nil
+	}
+
+	return aws.Bool(result)
+}
+
+func (b *terraformBlock) stringList(name string) []*string {
+	var result []string
+	if !b.value(name, cty.List(cty.String), &result) {
+		return nil
+	}
+
+	return aws.StringSlice(result)
+}
+
+func (b *terraformBlock) stringMap(name string) map[string]string {
+	var result map[string]string
+	if !b.value(name, cty.Map(cty.String), &result) {
+		return nil
+	}
+
+	return result
+}
+
+func (b *terraformBlock) throughput() *dynamodb.ProvisionedThroughput {
+	return &dynamodb.ProvisionedThroughput{
+		ReadCapacityUnits:  b.int64("read_capacity"),
+		WriteCapacityUnits: b.int64("write_capacity"),
+	}
+}
+
+func (b *terraformBlock) projection() *dynamodb.Projection {
+	return &dynamodb.Projection{
+		ProjectionType:   b.string("projection_type"),
+		NonKeyAttributes: b.stringList("non_key_attributes"),
+	}
+}
+
+// newHCLDecodeError creates dynamotest.DecodeError pointing at the first error of diagnostics
+func newHCLDecodeError(input dynamotest.Definition, diags hcl.Diagnostics, message string) *dynamotest.DecodeError {
+	decodeErr := &dynamotest.DecodeError{Source: input.Source}
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+
+		decodeErr.Err = errors.Errorf("%s: %s", message, diag.Summary)
+		if diag.Detail != "" {
+			decodeErr.Err = errors.Errorf("%s: %s; %s", message, diag.Summary, diag.Detail)
+		}
+		if diag.Subject != nil {
+			decodeErr.Line = diag.Subject.Start.Line
+			decodeErr.Column = diag.Subject.Start.Column
+		}
+		break
+	}
+
+	return decodeErr
+}
@@ -0,0 +1,177 @@
+package terraform_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/eps90/dynamotest/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformMigrationDecoder(t *testing.T) {
+	decoder := terraform.NewMigrationDecoder(map[string]string{
+		"environment":   "test",
+		"read_capacity": "5",
+	})
+	contents, err := ioutil.ReadFile("test_resources/tables.tf")
+	require.NoError(t, err)
+
+	actual, err := decoder.Decode(definition("test_resources/tables.tf", "tables", string(contents)))
+
+	require.NoError(t, err)
+	require.Equal(t, []*dynamotest.TableDefinition{
+		createSampleTerraformTableDefinition(),
+		{
+			CreateTableInput: &dynamodb.CreateTableInput{
+				TableName:   aws.String("owners"),
+				BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+				AttributeDefinitions: []*dynamodb.AttributeDefinition{
+					{AttributeName: aws.String("OwnerID"), AttributeType: aws.String("N")},
+				},
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{AttributeName: aws.String("OwnerID"), KeyType: aws.String("HASH")},
+				},
+			},
+		},
+	}, actual)
+}
+
+func TestTerraformMigrationDecoderMissingVariable(t *testing.T) {
+	decoder := terraform.NewMigrationDecoder(nil)
+	contents, err := ioutil.ReadFile("test_resources/tables.tf")
+	require.NoError(t, err)
+
+	_, err = decoder.Decode(definition("test_resources/tables.tf", "tables", string(contents)))
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, 13, decodeErr.Line)
+	require.Equal(t, 23, decodeErr.Column)
+	require.Contains(t, err.Error(), "tables.tf:13:23: resource 'aws_dynamodb_table.pets': Unsupported attribute")
+}
+
+func TestTerraformMigrationDecoderInvalidSyntax(t *testing.T) {
+	decoder := terraform.NewMigrationDecoder(nil)
+
+	_, err := decoder.Decode(definition("tables.tf", "tables", "resource \"aws_dynamodb_table\" \"pets\" {\n  name = \n}\n"))
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, 2, decodeErr.Line)
+	require.Contains(t, err.Error(), "cannot parse terraform file")
+}
+
+func TestTerraformMigrationDecoderInvalidIndexAttribute(t *testing.T) {
+	decoder := terraform.NewMigrationDecoder(nil)
+	contents := `resource "aws_dynamodb_table" "pets" {
+  name     = "pets"
+  hash_key = "ID"
+
+  global_secondary_index {
+    name            = "byOwner"
+    hash_key        = ["Owner"]
+    projection_type = "ALL"
+  }
+}
+`
+
+	_, err := decoder.Decode(definition("tables.tf", "tables", contents))
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, 7, decodeErr.Line)
+	require.Contains(t, err.Error(), "tables.tf:7:23: resource 'aws_dynamodb_table.pets': Invalid value of hash_key")
+}
+
+func TestTerraformMigrationDecoderDisablesTTLByDefault(t *testing.T) {
+	decoder := terraform.NewMigrationDecoder(nil)
+	contents := `resource "aws_dynamodb_table" "pets" {
+  name     = "pets"
+  hash_key = "ID"
+
+  ttl {
+    attribute_name = "ExpiresAt"
+  }
+}
+`
+
+	actual, err := decoder.Decode(definition("tables.tf", "tables", contents))
+
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, &dynamodb.TimeToLiveSpecification{
+		AttributeName: aws.String("ExpiresAt"),
+		Enabled:       aws.Bool(false),
+	}, actual[0].TimeToLiveSpecification)
+}
+
+func createSampleTerraformTableDefinition() *dynamotest.TableDefinition {
+	return &dynamotest.TableDefinition{
+		CreateTableInput: &dynamodb.CreateTableInput{
+			TableName:   aws.String("pets-test"),
+			BillingMode: aws.String(dynamodb.BillingModeProvisioned),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("ID"), AttributeType: aws.String("S")},
+				{AttributeName: aws.String("Name"), AttributeType: aws.String("S")},
+				{AttributeName: aws.String("Owner"), AttributeType: aws.String("S")},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("ID"), KeyType: aws.String("HASH")},
+				{AttributeName: aws.String("Name"), KeyType: aws.String("RANGE")},
+			},
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(5),
+				WriteCapacityUnits: aws.Int64(10),
+			},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+				{
+					IndexName: aws.String("byOwner"),
+					KeySchema: []*dynamodb.KeySchemaElement{
+						{AttributeName: aws.String("Owner"), KeyType: aws.String("HASH")},
+						{AttributeName: aws.String("Name"), KeyType: aws.String("RANGE")},
+					},
+					Projection: &dynamodb.Projection{
+						ProjectionType:   aws.String("INCLUDE"),
+						NonKeyAttributes: aws.StringSlice([]string{"Age"}),
+					},
+					ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+						ReadCapacityUnits:  aws.Int64(1),
+						WriteCapacityUnits: aws.Int64(1),
+					},
+				},
+			},
+			LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndex{
+				{
+					IndexName: aws.String("byOwnerLocal"),
+					KeySchema: []*dynamodb.KeySchemaElement{
+						{AttributeName: aws.String("ID"), KeyType: aws.String("HASH")},
+						{AttributeName: aws.String("Owner"), KeyType: aws.String("RANGE")},
+					},
+					Projection: &dynamodb.Projection{ProjectionType: aws.String("KEYS_ONLY")},
+				},
+			},
+			StreamSpecification: &dynamodb.StreamSpecification{
+				StreamEnabled:  aws.Bool(true),
+				StreamViewType: aws.String("NEW_AND_OLD_IMAGES"),
+			},
+			Tags: []*dynamodb.Tag{
+				{Key: aws.String("team"), Value: aws.String("pets")},
+			},
+		},
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String("ExpiresAt"),
+			Enabled:       aws.Bool(true),
+		},
+	}
+}
+
+func definition(path, name, contents string) dynamotest.Definition {
+	return dynamotest.Definition{
+		Source:   dynamotest.Source{Path: path, Name: name},
+		Contents: []byte(contents),
+	}
+}
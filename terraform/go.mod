@@ -0,0 +1,27 @@
+module github.com/eps90/dynamotest/terraform
+
+go 1.18
+
+require (
+	github.com/aws/aws-sdk-go v1.23.13
+	github.com/eps90/dynamotest v0.0.0-20261018102416-113b7b901b92
+	github.com/hashicorp/hcl/v2 v2.19.1
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.6.1
+	github.com/zclconf/go-cty v1.13.0
+)
+
+require (
+	github.com/agext/levenshtein v1.2.1 // indirect
+	github.com/apparentlymart/go-textseg/v13 v13.0.0 // indirect
+	github.com/apparentlymart/go-textseg/v15 v15.0.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/jmespath/go-jmespath v0.0.0-20180206201540-c2b33e8439af // indirect
+	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// replace builds the module against the root module of this repository, it's ignored by modules requiring it
+replace github.com/eps90/dynamotest => ../
@@ -0,0 +1,75 @@
+variable "environment" {
+  type    = string
+  default = "dev"
+}
+
+variable "read_capacity" {
+  type = number
+}
+
+resource "aws_dynamodb_table" "pets" {
+  name           = "pets-${var.environment}"
+  billing_mode   = "PROVISIONED"
+  read_capacity  = var.read_capacity
+  write_capacity = 10
+  hash_key       = "ID"
+  range_key      = "Name"
+
+  attribute {
+    name = "ID"
+    type = "S"
+  }
+
+  attribute {
+    name = "Name"
+    type = "S"
+  }
+
+  attribute {
+    name = "Owner"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name               = "byOwner"
+    hash_key           = "Owner"
+    range_key          = "Name"
+    projection_type    = "INCLUDE"
+    non_key_attributes = ["Age"]
+    read_capacity      = 1
+    write_capacity     = 1
+  }
+
+  local_secondary_index {
+    name            = "byOwnerLocal"
+    range_key       = "Owner"
+    projection_type = "KEYS_ONLY"
+  }
+
+  ttl {
+    attribute_name = "ExpiresAt"
+    enabled        = true
+  }
+
+  stream_enabled   = true
+  stream_view_type = "NEW_AND_OLD_IMAGES"
+
+  tags = {
+    team = "pets"
+  }
+}
+
+resource "aws_dynamodb_table" "owners" {
+  name         = "owners"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "OwnerID"
+
+  attribute {
+    name = "OwnerID"
+    type = "N"
+  }
+}
+
+resource "aws_sqs_queue" "pets" {
+  name = "pets"
+}
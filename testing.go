@@ -0,0 +1,31 @@
+package dynamotest
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// New creates DynamoTester dedicated to given test, configured with given options.
+// Unless WithTableNameResolver is used, table names get suffixes derived from the test name, so every test
+// and subtest, even run with t.Parallel, works on its own set of tables. Invalid options and fixtures failures
+// in MustLoadFixtures are reported with t.Fatalf and all created tables are dropped when the test completes.
+// The seed of templated fixtures is logged, so a failing run can be replayed.
+func New(tb testing.TB, dynamoSvc dynamodbiface.DynamoDBAPI, opts ...Option) *DynamoTester {
+	tb.Helper()
+
+	testOpts := []Option{withTestName(tb.Name())}
+	tester, err := NewDynamoTester(dynamoSvc, append(testOpts, opts...)...)
+	if err != nil {
+		tb.Fatalf("Cannot create DynamoTester: %v", err)
+		return nil
+	}
+	if templateDecoder, ok := tester.FixturesDecoder.(*TemplateFixturesDecoder); ok {
+		tb.Logf("Templated fixtures use seed %d, set %s=%d to replay them", templateDecoder.Seed, SeedEnv, templateDecoder.Seed)
+	}
+
+	tester.tb = tb
+	tester.Cleanup(tb)
+
+	return tester
+}
@@ -0,0 +1,51 @@
+package dynamotest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+				t.Parallel()
+				tester := dynamotest.New(t, dynamoSvc)
+				useSampleDefinitions(tester)
+
+				tester.MustLoadFixtures()
+
+				tableName := tester.TableNameFor("tableName")
+				require.True(t, strings.HasPrefix(tableName, "tableName_TestNew_group_case_"), tableName)
+				require.Len(t, dynamoSvc.items(tableName), 3)
+			})
+		}
+	})
+
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func TestNewFailsTestOnFixturesError(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	tester := dynamotest.New(tb, newFakeDynamoDB())
+	useSampleDefinitions(tester)
+
+	tester.MustLoadFixtures("unknown")
+
+	require.Contains(t, tb.fatal, "Cannot load fixtures")
+}
+
+// recordingTB records fatal failures instead of stopping the test
+type recordingTB struct {
+	testing.TB
+	fatal string
+}
+
+func (tb *recordingTB) Fatalf(format string, args ...interface{}) {
+	tb.fatal = fmt.Sprintf(format, args...)
+}
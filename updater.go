@@ -0,0 +1,79 @@
+package dynamotest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+// ItemUpdater defines an interface for partial updates of single items, used by "update" operations of fixtures
+type ItemUpdater interface {
+	// UpdateItem sets given attributes and removes attributes of given names of the item of given key
+	UpdateItem(tableName string, key, attributes map[string]*dynamodb.AttributeValue, remove []string) error
+}
+
+// DefaultItemUpdater updates items with UpdateItem, creating them if they don't exist
+type DefaultItemUpdater struct {
+	dynamoSvc dynamodbiface.DynamoDBAPI
+}
+
+// NewDefaultItemUpdater creates new instance of DefaultItemUpdater
+func NewDefaultItemUpdater(dynamoSvc dynamodbiface.DynamoDBAPI) *DefaultItemUpdater {
+	return &DefaultItemUpdater{dynamoSvc: dynamoSvc}
+}
+
+func (u *DefaultItemUpdater) UpdateItem(
+	tableName string,
+	key, attributes map[string]*dynamodb.AttributeValue,
+	remove []string,
+) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key:       key,
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	expressionNames := make(map[string]*string)
+	expressionValues := make(map[string]*dynamodb.AttributeValue)
+	var set, removed, expression []string
+	for i, name := range names {
+		placeholder := "a" + strconv.Itoa(i)
+		set = append(set, "#"+placeholder+" = :"+placeholder)
+		expressionNames["#"+placeholder] = aws.String(name)
+		expressionValues[":"+placeholder] = attributes[name]
+	}
+	for i, name := range remove {
+		placeholder := "#r" + strconv.Itoa(i)
+		removed = append(removed, placeholder)
+		expressionNames[placeholder] = aws.String(name)
+	}
+
+	if len(set) > 0 {
+		expression = append(expression, "SET "+strings.Join(set, ", "))
+		input.ExpressionAttributeValues = expressionValues
+	}
+	if len(removed) > 0 {
+		expression = append(expression, "REMOVE "+strings.Join(removed, ", "))
+	}
+	if len(expression) > 0 {
+		input.UpdateExpression = aws.String(strings.Join(expression, " "))
+		input.ExpressionAttributeNames = expressionNames
+	}
+
+	_, err := u.dynamoSvc.UpdateItem(input)
+	if err != nil {
+		return errors.Wrapf(err, "fixtures: cannot update item of table '%s'", tableName)
+	}
+
+	return nil
+}
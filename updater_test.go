@@ -0,0 +1,77 @@
+package dynamotest_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultItemUpdater(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	_, err := dynamoSvc.CreateTable(&dynamodb.CreateTableInput{
+		TableName: aws.String("tableName"),
+		KeySchema: []*dynamodb.KeySchemaElement{{AttributeName: aws.String("ID"), KeyType: aws.String(dynamodb.KeyTypeHash)}},
+	})
+	require.NoError(t, err)
+	_, err = dynamoSvc.BatchWriteItem(&dynamodb.BatchWriteItemInput{RequestItems: dynamotest.TableWriteRequests{
+		"tableName": {{PutRequest: &dynamodb.PutRequest{Item: marshalMap(map[string]interface{}{"ID": 1, "Name": "Abc", "Age": 3})}}},
+	}})
+	require.NoError(t, err)
+	updater := dynamotest.NewDefaultItemUpdater(dynamoSvc)
+
+	err = updater.UpdateItem(
+		"tableName",
+		marshalMap(map[string]interface{}{"ID": 1}),
+		marshalMap(map[string]interface{}{"Name": "Changed", "Active": true}),
+		[]string{"Age"},
+	)
+
+	require.NoError(t, err)
+	require.Len(t, dynamoSvc.updateItemCalls, 1)
+	require.Equal(t, "SET #a0 = :a0, #a1 = :a1 REMOVE #r0", aws.StringValue(dynamoSvc.updateItemCalls[0].UpdateExpression))
+	require.Equal(t, []map[string]*dynamodb.AttributeValue{
+		marshalMap(map[string]interface{}{"ID": 1, "Name": "Changed", "Active": true}),
+	}, dynamoSvc.items("tableName"))
+}
+
+func TestDynamoTesterLoadFixturesAppliesOperationsInOrder(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+	tester.FixturesLoader = staticLoader{
+		"scenario": []byte(`{"extends": "base", "table": "tableName", "items": [
+			{"$op": "delete", "ID": 1},
+			{"$op": "update", "ID": 2, "Name": "Updated", "$remove": ["Age"]},
+			{"ID": 3, "Name": "Cde"},
+			{"$op": "delete", "ID": 3},
+			{"ID": 4, "Name": "Def"}
+		]}`),
+		"base": []byte(`{"table": "tableName", "items": [{"ID": 1, "Name": "Abc"}, {"ID": 2, "Name": "Bca", "Age": 3}]}`),
+	}
+
+	err := tester.LoadFixtures("scenario")
+
+	require.NoError(t, err)
+	require.Equal(t, []map[string]*dynamodb.AttributeValue{
+		marshalMap(map[string]interface{}{"ID": 2, "Name": "Updated"}),
+		marshalMap(map[string]interface{}{"ID": 4, "Name": "Def"}),
+	}, dynamoSvc.items(tester.TableNameFor("tableName")))
+	require.Len(t, dynamoSvc.batchWriteCalls, 4)
+	require.Len(t, dynamoSvc.updateItemCalls, 1)
+}
+
+func TestDynamoTesterLoadFixturesWithoutUpdater(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createSampleDynamoTester(dynamoSvc)
+	tester.Updater = nil
+	tester.FixturesLoader = staticLoader{
+		"scenario": []byte(`{"table": "tableName", "items": [{"$op": "update", "ID": 2, "Name": "Updated"}]}`),
+	}
+
+	err := tester.LoadFixtures("scenario")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "fixtures: cannot update item 0 of table 'tableName': tester has no Updater")
+}
@@ -0,0 +1,263 @@
+package dynamotest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+// maxItemSize is the maximum size of DynamoDB item, including lengths of attribute names
+const maxItemSize = 400 * 1024
+
+// Violation describes an item of a fixture that doesn't match the migration of its table
+type Violation struct {
+	// Source is the fixture the item comes from
+	Source Source
+	// TableName is the table of the item, as named in the fixture
+	TableName string
+	// Index is the position of the item among items of the table in the fixture
+	Index   int
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: item %d of table '%s': %s", v.Source.Path, v.Index, v.TableName, v.Message)
+}
+
+// FixturesValidationError lists all items of fixtures that would be rejected by DynamoDB
+type FixturesValidationError struct {
+	Violations []Violation
+}
+
+func (e *FixturesValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.String())
+	}
+
+	return fmt.Sprintf("fixtures: %d invalid item(s): %s", len(e.Violations), strings.Join(messages, "; "))
+}
+
+// validateFixtures checks requests of tables having migrations: presence and types of key attributes,
+// types of index key attributes and sizes of items. Keys written more than once are not reported,
+// as writeOperations never puts them into the same BatchWriteItem call, so later puts replace earlier items.
+func (t *DynamoTester) validateFixtures(operations TableOperations, origins map[*Operation]itemOrigin) error {
+	tableNames := make([]string, 0, len(operations))
+	for tableName := range operations {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	var violations []Violation
+	for _, tableName := range tableNames {
+		tableDefinition, err := t.Migrator.Definition(tableName)
+		var noMigrationErr *NoMigrationError
+		if errors.As(err, &noMigrationErr) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "fixtures: cannot validate fixtures")
+		}
+		v := newTableValidator(tableDefinition)
+
+		for _, operation := range operations[tableName] {
+			origin := origins[operation]
+			for _, message := range v.validate(operation) {
+				violations = append(violations, Violation{
+					Source:    origin.Source,
+					TableName: tableName,
+					Index:     origin.Index,
+					Message:   message,
+				})
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &FixturesValidationError{Violations: violations}
+	}
+
+	return nil
+}
+
+type tableValidator struct {
+	keyNames []string
+	// types of key attributes of the table and of its indexes
+	types map[string]string
+}
+
+func newTableValidator(tableDefinition *TableDefinition) *tableValidator {
+	v := &tableValidator{types: make(map[string]string)}
+	for _, element := range tableDefinition.KeySchema {
+		v.keyNames = append(v.keyNames, aws.StringValue(element.AttributeName))
+	}
+	for _, attribute := range tableDefinition.AttributeDefinitions {
+		v.types[aws.StringValue(attribute.AttributeName)] = aws.StringValue(attribute.AttributeType)
+	}
+
+	return v
+}
+
+func (v *tableValidator) validate(operation *Operation) []string {
+	var messages []string
+	var key, attributes map[string]*dynamodb.AttributeValue
+	switch operation.Op {
+	case OperationUpdate:
+		key, attributes = operation.splitUpdate(v.keyNames)
+	case OperationPut:
+		attributes = operation.Item
+		key = make(map[string]*dynamodb.AttributeValue, len(v.keyNames))
+		for _, name := range v.keyNames {
+			if value, ok := attributes[name]; ok {
+				key[name] = value
+			}
+		}
+		if size := itemSize(attributes); size > maxItemSize {
+			messages = append(messages, fmt.Sprintf("item size of %d bytes exceeds the limit of %d bytes", size, maxItemSize))
+		}
+	case OperationDelete:
+		key = operation.Key
+		for _, name := range sortedAttributeNames(key) {
+			if !v.isKeyAttribute(name) {
+				messages = append(messages, fmt.Sprintf("key of delete contains non-key attribute '%s'", name))
+			}
+		}
+	}
+
+	for _, name := range v.keyNames {
+		if _, ok := key[name]; !ok {
+			messages = append(messages, fmt.Sprintf("missing key attribute '%s'", name))
+		}
+	}
+	for _, name := range sortedAttributeNames(key) {
+		if message, ok := v.checkType(name, key[name]); !ok {
+			messages = append(messages, message)
+		}
+	}
+	for _, name := range sortedAttributeNames(attributes) {
+		if _, isKey := key[name]; isKey {
+			continue
+		}
+		if message, ok := v.checkType(name, attributes[name]); !ok {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+func (v *tableValidator) isKeyAttribute(name string) bool {
+	for _, keyName := range v.keyNames {
+		if keyName == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkType compares the type of key attribute of the table or of its index with the type of its definition
+func (v *tableValidator) checkType(name string, value *dynamodb.AttributeValue) (string, bool) {
+	expected, ok := v.types[name]
+	if !ok {
+		return "", true
+	}
+	if actual := attributeType(value); actual != expected {
+		return fmt.Sprintf("attribute '%s' must be of type %s, got %s", name, expected, actual), false
+	}
+
+	return "", true
+}
+
+func attributeType(value *dynamodb.AttributeValue) string {
+	switch {
+	case value == nil:
+		return "nothing"
+	case value.S != nil:
+		return dynamodb.ScalarAttributeTypeS
+	case value.N != nil:
+		return dynamodb.ScalarAttributeTypeN
+	case value.B != nil:
+		return dynamodb.ScalarAttributeTypeB
+	case value.BOOL != nil:
+		return "BOOL"
+	case value.NULL != nil:
+		return "NULL"
+	case value.SS != nil:
+		return "SS"
+	case value.NS != nil:
+		return "NS"
+	case value.BS != nil:
+		return "BS"
+	case value.L != nil:
+		return "L"
+	case value.M != nil:
+		return "M"
+	default:
+		return "nothing"
+	}
+}
+
+// itemSize estimates the size of the item the way DynamoDB counts it
+func itemSize(item map[string]*dynamodb.AttributeValue) int {
+	size := 0
+	for name, value := range item {
+		size += len(name) + attributeSize(value)
+	}
+
+	return size
+}
+
+func attributeSize(value *dynamodb.AttributeValue) int {
+	switch {
+	case value == nil:
+		return 0
+	case value.S != nil:
+		return len(aws.StringValue(value.S))
+	case value.N != nil:
+		return numberSize(aws.StringValue(value.N))
+	case value.B != nil:
+		return len(value.B)
+	case value.BOOL != nil, value.NULL != nil:
+		return 1
+	case value.SS != nil:
+		size := 0
+		for _, s := range value.SS {
+			size += len(aws.StringValue(s))
+		}
+		return size
+	case value.NS != nil:
+		size := 0
+		for _, n := range value.NS {
+			size += numberSize(aws.StringValue(n))
+		}
+		return size
+	case value.BS != nil:
+		size := 0
+		for _, b := range value.BS {
+			size += len(b)
+		}
+		return size
+	case value.L != nil:
+		size := 3
+		for _, element := range value.L {
+			size += 1 + attributeSize(element)
+		}
+		return size
+	case value.M != nil:
+		return 3 + len(value.M) + itemSize(value.M)
+	default:
+		return 0
+	}
+}
+
+// numberSize is the size of a number, i.e. one byte per two significant digits plus one byte
+func numberSize(n string) int {
+	digits := strings.TrimLeft(strings.NewReplacer("-", "", ".", "").Replace(strings.SplitN(strings.ToLower(n), "e", 2)[0]), "0")
+
+	return (len(strings.TrimRight(digits, "0"))+1)/2 + 1
+}
@@ -0,0 +1,141 @@
+package dynamotest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamoTesterLoadFixturesReportsAllViolations(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createValidatingDynamoTester(dynamoSvc, staticLoader{
+		"first": []byte(`{"table": "orders", "items": [
+			{"PK": "USER#1", "SK": "ORDER#1", "Total": 10},
+			{"PK": "USER#1"},
+			{"PK": 1, "SK": "ORDER#2", "Total": "ten"}
+		]}`),
+		"second": []byte(`{"table": "orders", "items": [
+			{"PK": "USER#1", "SK": "ORDER#1"},
+			{"$op": "delete", "PK": "USER#1", "SK": "ORDER#1", "Total": 10},
+			{"PK": "USER#1", "SK": "ORDER#3", "Payload": "` + strings.Repeat("x", 400*1024) + `"}
+		]}`),
+	})
+
+	err := tester.LoadFixtures()
+
+	var validationErr *dynamotest.FixturesValidationError
+	require.True(t, errors.As(err, &validationErr))
+	messages := make([]string, 0, len(validationErr.Violations))
+	for _, violation := range validationErr.Violations {
+		messages = append(messages, violation.String())
+	}
+	require.Equal(t, []string{
+		"first.json: item 1 of table 'orders': missing key attribute 'SK'",
+		"first.json: item 2 of table 'orders': attribute 'PK' must be of type S, got N",
+		"first.json: item 2 of table 'orders': attribute 'Total' must be of type N, got S",
+		"second.json: item 1 of table 'orders': key of delete contains non-key attribute 'Total'",
+		"second.json: item 2 of table 'orders': item size of 409624 bytes exceeds the limit of 409600 bytes",
+	}, messages)
+	require.Equal(t, "first.json", validationErr.Violations[0].Source.Path)
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func TestDynamoTesterLoadFixturesAcceptsValidOperations(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createValidatingDynamoTester(dynamoSvc, staticLoader{
+		"orders": []byte(`{"table": "orders", "items": [
+			{"PK": "USER#1", "SK": "ORDER#1", "Total": 10},
+			{"$op": "delete", "PK": "USER#1", "SK": "ORDER#1"},
+			{"PK": "USER#1", "SK": "ORDER#1"},
+			{"$op": "update", "PK": "USER#1", "SK": "ORDER#1", "Total": 12},
+			{"PK": "USER#1", "SK": "ORDER#1", "Total": 13}
+		]}`),
+	})
+
+	err := tester.LoadFixtures()
+
+	require.NoError(t, err)
+	require.Len(t, dynamoSvc.items(tester.TableNameFor("orders")), 1)
+}
+
+func TestDynamoTesterLoadFixturesAcceptsKeysPutByManyFixtures(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createValidatingDynamoTester(dynamoSvc, staticLoader{
+		"a": []byte(`{"table": "orders", "items": [{"PK": "USER#1", "SK": "ORDER#1", "Total": 10}]}`),
+		"b": []byte(`{"table": "orders", "items": [{"PK": "USER#1", "SK": "ORDER#1", "Total": 20}]}`),
+	})
+
+	err := tester.LoadFixtures("a", "b")
+
+	require.NoError(t, err)
+	items := dynamoSvc.items(tester.TableNameFor("orders"))
+	require.Len(t, items, 1)
+	require.Equal(t, "20", aws.StringValue(items[0]["Total"].N))
+	require.Len(t, dynamoSvc.batchWriteCalls, 2)
+}
+
+func TestDynamoTesterLoadFixturesReportsPatchedItemsAtPatch(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createValidatingDynamoTester(dynamoSvc, staticLoader{
+		"base": []byte(`{"table": "orders", "items": [
+			{"PK": "USER#1", "SK": "ORDER#1", "Total": 10},
+			{"PK": "USER#1", "SK": "ORDER#2", "Total": 20}
+		]}`),
+		"child": []byte(`{"extends": "base", "table": "orders", "items": [
+			{"PK": "USER#1", "SK": "ORDER#2", "Total": "twenty"}
+		]}`),
+	})
+
+	err := tester.LoadFixtures("child")
+
+	var validationErr *dynamotest.FixturesValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Violations, 1)
+	require.Equal(t, "child.json: item 0 of table 'orders': attribute 'Total' must be of type N, got S", validationErr.Violations[0].String())
+}
+
+func TestDynamoTesterLoadFixturesFailsOnUnreadableMigrations(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	tester := createValidatingDynamoTester(dynamoSvc, staticLoader{
+		"orders": []byte(`{"table": "orders", "items": [{"PK": "USER#1", "SK": "ORDER#1"}]}`),
+	})
+	tester.Migrator.MigrationsLoader = staticLoader{"orders": []byte(`{"TableName": `)}
+
+	err := tester.LoadFixtures()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "fixtures: cannot validate fixtures")
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func createSampleOrdersMigrationBytes() []byte {
+	return []byte(`{
+		"TableName": "orders",
+		"AttributeDefinitions": [
+			{"AttributeName": "PK", "AttributeType": "S"},
+			{"AttributeName": "SK", "AttributeType": "S"},
+			{"AttributeName": "Total", "AttributeType": "N"}
+		],
+		"KeySchema": [
+			{"AttributeName": "PK", "KeyType": "HASH"},
+			{"AttributeName": "SK", "KeyType": "RANGE"}
+		],
+		"GlobalSecondaryIndexes": [{
+			"IndexName": "byTotal",
+			"KeySchema": [{"AttributeName": "PK", "KeyType": "HASH"}, {"AttributeName": "Total", "KeyType": "RANGE"}],
+			"Projection": {"ProjectionType": "ALL"}
+		}],
+		"BillingMode": "PAY_PER_REQUEST"
+	}`)
+}
+
+func createValidatingDynamoTester(dynamoSvc *fakeDynamoDB, fixtures staticLoader) *dynamotest.DynamoTester {
+	tester := createFixturesDynamoTester(dynamoSvc, fixtures)
+	tester.Migrator.MigrationsLoader = staticLoader{"orders": createSampleOrdersMigrationBytes()}
+
+	return tester
+}
@@ -0,0 +1,110 @@
+package dynamotest
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultWaitTimeout      = 2 * time.Minute
+	defaultWaitPollInterval = 500 * time.Millisecond
+)
+
+// TableWaiter defines an interface for waiting until a table reaches expected state
+type TableWaiter interface {
+	WaitUntilActive(tableName string) error
+	WaitUntilDeleted(tableName string) error
+}
+
+// DescribeTableWaiter polls DescribeTable until the table reaches expected state or the timeout passes
+type DescribeTableWaiter struct {
+	dynamoSvc    dynamodbiface.DynamoDBAPI
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+// NewDescribeTableWaiter creates new instance of DescribeTableWaiter with default timeout and poll interval
+func NewDescribeTableWaiter(dynamoSvc dynamodbiface.DynamoDBAPI) *DescribeTableWaiter {
+	return &DescribeTableWaiter{
+		dynamoSvc:    dynamoSvc,
+		Timeout:      defaultWaitTimeout,
+		PollInterval: defaultWaitPollInterval,
+	}
+}
+
+// WaitUntilActive waits until the table and all of its global secondary indexes are ACTIVE.
+// Missing table is treated as not created yet.
+func (w *DescribeTableWaiter) WaitUntilActive(tableName string) error {
+	return w.wait(tableName, "active", func(output *dynamodb.DescribeTableOutput, err error) (bool, error) {
+		if err != nil {
+			if isAWSErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		return isTableActive(output.Table), nil
+	})
+}
+
+// WaitUntilDeleted waits until DescribeTable reports that the table doesn't exist
+func (w *DescribeTableWaiter) WaitUntilDeleted(tableName string) error {
+	return w.wait(tableName, "deleted", func(_ *dynamodb.DescribeTableOutput, err error) (bool, error) {
+		if err != nil {
+			if isAWSErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		return false, nil
+	})
+}
+
+func (w *DescribeTableWaiter) wait(
+	tableName string,
+	state string,
+	done func(output *dynamodb.DescribeTableOutput, err error) (bool, error),
+) error {
+	deadline := time.Now().Add(w.Timeout)
+	for {
+		finished, err := done(w.dynamoSvc.DescribeTable(&dynamodb.DescribeTableInput{
+			TableName: aws.String(tableName),
+		}))
+		if err != nil {
+			return errors.Wrapf(err, "wait: cannot describe table '%s'", tableName)
+		}
+		if finished {
+			return nil
+		}
+		if !time.Now().Add(w.PollInterval).Before(deadline) {
+			return errors.Errorf("wait: table '%s' is not %s after %s", tableName, state, w.Timeout)
+		}
+
+		time.Sleep(w.PollInterval)
+	}
+}
+
+func isTableActive(table *dynamodb.TableDescription) bool {
+	if aws.StringValue(table.TableStatus) != dynamodb.TableStatusActive {
+		return false
+	}
+
+	for _, gsi := range table.GlobalSecondaryIndexes {
+		if gsi.IndexStatus != nil && *gsi.IndexStatus != dynamodb.IndexStatusActive {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isAWSErrorCode(err error, code string) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == code
+}
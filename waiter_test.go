@@ -0,0 +1,59 @@
+package dynamotest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeTableWaiterWaitsUntilActive(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	dynamoSvc.creatingDescribes = 3
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	waiter := createFastWaiter(dynamoSvc)
+
+	err := waiter.WaitUntilActive("tableName")
+
+	require.NoError(t, err)
+	require.True(t, dynamoSvc.tables["tableName"].isActive())
+}
+
+func TestDescribeTableWaiterWaitsUntilDeleted(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	dynamoSvc.deletingDescribes = 3
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	_, _ = dynamoSvc.DeleteTable(createSampleDeleteTableInput())
+	waiter := createFastWaiter(dynamoSvc)
+
+	err := waiter.WaitUntilDeleted("tableName")
+
+	require.NoError(t, err)
+	require.Empty(t, dynamoSvc.tableNames())
+}
+
+func TestDescribeTableWaiterTimesOut(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	dynamoSvc.creatingDescribes = 1000
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	waiter := createFastWaiter(dynamoSvc)
+	waiter.Timeout = 5 * time.Millisecond
+
+	err := waiter.WaitUntilActive("tableName")
+
+	require.Error(t, err)
+}
+
+func createFastWaiter(dynamoSvc *fakeDynamoDB) *dynamotest.DescribeTableWaiter {
+	waiter := dynamotest.NewDescribeTableWaiter(dynamoSvc)
+	waiter.PollInterval = time.Millisecond
+
+	return waiter
+}
+
+func createSampleDeleteTableInput() *dynamodb.DeleteTableInput {
+	return &dynamodb.DeleteTableInput{TableName: aws.String("tableName")}
+}
@@ -0,0 +1,159 @@
+package dynamotest
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+// maxBatchWriteRequests is the maximum number of write requests accepted by a single BatchWriteItem call
+const maxBatchWriteRequests = 25
+
+const (
+	defaultMaxRetries = 8
+	defaultBaseDelay  = 50 * time.Millisecond
+	defaultMaxDelay   = 5 * time.Second
+)
+
+// BatchWriter defines an interface for writing collection of write requests into DynamoDB tables
+type BatchWriter interface {
+	WriteBatch(requests TableWriteRequests) error
+}
+
+// ChunkedBatchWriter splits write requests into chunks accepted by BatchWriteItem
+// and retries unprocessed items with exponential backoff and jitter.
+type ChunkedBatchWriter struct {
+	dynamoSvc dynamodbiface.DynamoDBAPI
+	// MaxRetries is the number of retries of unprocessed items in a single chunk
+	MaxRetries int
+	// BaseDelay is the upper bound of the delay before the first retry, doubled with every next one
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries
+	MaxDelay time.Duration
+}
+
+// NewChunkedBatchWriter creates new instance of ChunkedBatchWriter with default retry settings
+func NewChunkedBatchWriter(dynamoSvc dynamodbiface.DynamoDBAPI) *ChunkedBatchWriter {
+	return &ChunkedBatchWriter{
+		dynamoSvc:  dynamoSvc,
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultBaseDelay,
+		MaxDelay:   defaultMaxDelay,
+	}
+}
+
+// WriteBatch writes all requests in chunks of 25.
+// Items that are still unprocessed after MaxRetries are reported with UnprocessedItemsError.
+func (w *ChunkedBatchWriter) WriteBatch(requests TableWriteRequests) error {
+	unprocessed := make(TableWriteRequests)
+	for _, chunk := range chunkWriteRequests(requests, maxBatchWriteRequests) {
+		left, err := w.writeChunk(chunk)
+		if err != nil {
+			return err
+		}
+
+		for tableName, r := range left {
+			unprocessed[tableName] = append(unprocessed[tableName], r...)
+		}
+	}
+
+	if len(unprocessed) > 0 {
+		return &UnprocessedItemsError{Items: unprocessed}
+	}
+
+	return nil
+}
+
+func (w *ChunkedBatchWriter) writeChunk(chunk TableWriteRequests) (TableWriteRequests, error) {
+	pending := chunk
+	for attempt := 0; ; attempt++ {
+		output, err := w.dynamoSvc.BatchWriteItem(&dynamodb.BatchWriteItemInput{
+			RequestItems: pending,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "fixtures: cannot write items")
+		}
+
+		pending = output.UnprocessedItems
+		if len(pending) == 0 || attempt >= w.MaxRetries {
+			return pending, nil
+		}
+
+		time.Sleep(w.backoff(attempt))
+	}
+}
+
+// backoff returns a random delay between zero and exponentially growing upper bound ("full jitter")
+func (w *ChunkedBatchWriter) backoff(attempt int) time.Duration {
+	if w.BaseDelay <= 0 {
+		return 0
+	}
+
+	ceiling := w.BaseDelay << uint(attempt)
+	if ceiling <= 0 || (w.MaxDelay > 0 && ceiling > w.MaxDelay) {
+		ceiling = w.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
+
+// chunkWriteRequests splits requests into chunks containing at most size requests in total.
+// Tables are visited in alphabetical order so the result is deterministic.
+func chunkWriteRequests(requests TableWriteRequests, size int) []TableWriteRequests {
+	tableNames := make([]string, 0, len(requests))
+	for tableName := range requests {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	var result []TableWriteRequests
+	current := make(TableWriteRequests)
+	currentSize := 0
+	for _, tableName := range tableNames {
+		for _, r := range requests[tableName] {
+			if currentSize == size {
+				result = append(result, current)
+				current = make(TableWriteRequests)
+				currentSize = 0
+			}
+			current[tableName] = append(current[tableName], r)
+			currentSize++
+		}
+	}
+
+	if currentSize > 0 {
+		result = append(result, current)
+	}
+
+	return result
+}
+
+// UnprocessedItemsError is returned when some write requests haven't been processed within the retry budget
+type UnprocessedItemsError struct {
+	// Items contains every write request that never landed, grouped by table
+	Items TableWriteRequests
+}
+
+func (e *UnprocessedItemsError) Error() string {
+	tableNames := make([]string, 0, len(e.Items))
+	for tableName := range e.Items {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	counts := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		counts = append(counts, fmt.Sprintf("%s (%d)", tableName, len(e.Items[tableName])))
+	}
+
+	return fmt.Sprintf("fixtures: items left unprocessed after retries: %s", strings.Join(counts, ", "))
+}
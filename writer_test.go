@@ -0,0 +1,68 @@
+package dynamotest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedBatchWriterSplitsRequests(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	writer := dynamotest.NewChunkedBatchWriter(dynamoSvc)
+
+	err := writer.WriteBatch(createSampleWriteRequests("tableName", 60))
+
+	require.NoError(t, err)
+	require.Len(t, dynamoSvc.batchWriteCalls, 3)
+	require.Len(t, dynamoSvc.items("tableName"), 60)
+}
+
+func TestChunkedBatchWriterRetriesUnprocessedItems(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	dynamoSvc.rejectWrites = 7
+	writer := dynamotest.NewChunkedBatchWriter(dynamoSvc)
+	writer.BaseDelay = 0
+
+	err := writer.WriteBatch(createSampleWriteRequests("tableName", 10))
+
+	require.NoError(t, err)
+	require.Len(t, dynamoSvc.batchWriteCalls, 2)
+	require.Len(t, dynamoSvc.items("tableName"), 10)
+}
+
+func TestChunkedBatchWriterReportsUnprocessedItems(t *testing.T) {
+	dynamoSvc := newFakeDynamoDB()
+	_, _ = dynamoSvc.CreateTable(createSampleCreateTableInput())
+	dynamoSvc.rejectWrites = 100
+	writer := dynamotest.NewChunkedBatchWriter(dynamoSvc)
+	writer.BaseDelay = 0
+	writer.MaxRetries = 2
+
+	requests := createSampleWriteRequests("tableName", 30)
+	err := writer.WriteBatch(requests)
+
+	require.IsType(t, &dynamotest.UnprocessedItemsError{}, err)
+	require.ElementsMatch(t, requests["tableName"], err.(*dynamotest.UnprocessedItemsError).Items["tableName"])
+	require.Len(t, dynamoSvc.batchWriteCalls, 6)
+}
+
+func createSampleWriteRequests(tableName string, count int) dynamotest.TableWriteRequests {
+	requests := make(dynamotest.TableWriteRequests)
+	for i := 0; i < count; i++ {
+		requests[tableName] = append(requests[tableName], &dynamodb.WriteRequest{
+			PutRequest: &dynamodb.PutRequest{
+				Item: marshalMap(map[string]interface{}{
+					"ID":   i,
+					"Name": fmt.Sprintf("Item %d", i),
+				}),
+			},
+		})
+	}
+
+	return requests
+}
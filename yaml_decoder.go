@@ -0,0 +1,264 @@
+package dynamotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLMigrationDecoder decodes YAML migrations into TableDefinition.
+// Migrations have the same structure as JSON ones. Every document of a multi-document file,
+// separated by "---", defines a separate table.
+type YAMLMigrationDecoder struct {
+}
+
+func (*YAMLMigrationDecoder) Decode(input Definition) ([]*TableDefinition, error) {
+	documents, err := decodeYAMLDocuments(input, "cannot parse migration file", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*TableDefinition
+	for _, document := range documents {
+		table, err := decodeMigration(document.contents)
+		if err != nil {
+			return nil, &DecodeError{Source: input.Source, Line: document.line, Err: err}
+		}
+		result = append(result, table)
+	}
+
+	return result, nil
+}
+
+// YAMLFixturesDecoder decodes YAML fixtures, having the same structure as JSON ones, into TableOperations.
+// Every document of a multi-document file, separated by "---", is a separate fixture.
+type YAMLFixturesDecoder struct {
+}
+
+func NewYAMLFixturesDecoder() *YAMLFixturesDecoder {
+	return &YAMLFixturesDecoder{}
+}
+
+func (d *YAMLFixturesDecoder) Decode(input []Definition) (TableWriteRequests, error) {
+	return decodeWriteRequests(d, input)
+}
+
+func (*YAMLFixturesDecoder) DecodeOperations(input []Definition) (TableOperations, error) {
+	operations := make(TableOperations)
+	for _, fixtureDefinition := range input {
+		documents, err := decodeYAMLDocuments(fixtureDefinition, "cannot parse fixture", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, document := range documents {
+			fx, err := parseFixture(document.contents)
+			if err == nil {
+				err = fx.appendTo(operations)
+			}
+			if err != nil {
+				return nil, &DecodeError{Source: fixtureDefinition.Source, Line: document.line, Err: err}
+			}
+		}
+	}
+
+	return operations, nil
+}
+
+// yamlDocument is a single YAML document converted to JSON
+type yamlDocument struct {
+	contents []byte
+	line     int
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+):`)
+
+// yamlTagWriter writes JSON representation of a node with a local tag, e.g. "!Ref Param"
+type yamlTagWriter func(buf *bytes.Buffer, node *yaml.Node) error
+
+// decodeYAMLDocuments converts every non-empty document of the definition to JSON.
+// Nodes with local tags are written with writeTag, they're rejected when it's nil.
+func decodeYAMLDocuments(input Definition, message string, writeTag yamlTagWriter) ([]yamlDocument, error) {
+	var result []yamlDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(input.Contents))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			decodeErr := &DecodeError{Source: input.Source, Err: errors.Wrap(err, message)}
+			if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				decodeErr.Line, _ = strconv.Atoi(match[1])
+			}
+			return nil, decodeErr
+		}
+		if len(node.Content) == 0 || node.Content[0].ShortTag() == "!!null" {
+			continue
+		}
+
+		var buf bytes.Buffer
+		err = writeYAMLNodeAsJSON(&buf, node.Content[0], writeTag)
+		if err != nil {
+			return nil, &DecodeError{Source: input.Source, Line: node.Line, Err: errors.Wrap(err, message)}
+		}
+		result = append(result, yamlDocument{contents: buf.Bytes(), line: node.Content[0].Line})
+	}
+}
+
+// writeYAMLNodeAsJSON writes JSON representation of the node. Numbers keep their original text when it's valid JSON,
+// so they're not rounded on the way.
+func writeYAMLNodeAsJSON(buf *bytes.Buffer, node *yaml.Node, writeTag yamlTagWriter) error {
+	if isLocalYAMLTag(node.Tag) {
+		if writeTag == nil {
+			return errors.Errorf("line %d: unsupported tag '%s'", node.Line, node.Tag)
+		}
+		return writeTag(buf, node)
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return writeYAMLNodeAsJSON(buf, node.Content[0], writeTag)
+	case yaml.AliasNode:
+		return writeYAMLNodeAsJSON(buf, node.Alias, writeTag)
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeYAMLNodeAsJSON(buf, item, writeTag); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case yaml.MappingNode:
+		return writeYAMLMappingAsJSON(buf, node, writeTag)
+	case yaml.ScalarNode:
+		return writeYAMLScalarAsJSON(buf, node)
+	default:
+		return errors.Errorf("line %d: unsupported YAML node", node.Line)
+	}
+}
+
+func isLocalYAMLTag(tag string) bool {
+	return strings.HasPrefix(tag, "!") && !strings.HasPrefix(tag, "!!")
+}
+
+func writeYAMLMappingAsJSON(buf *bytes.Buffer, node *yaml.Node, writeTag yamlTagWriter) error {
+	keys, values, err := collectYAMLMapping(node)
+	if err != nil {
+		return err
+	}
+
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		if err := writeYAMLNodeAsJSON(buf, values[key], writeTag); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// collectYAMLMapping returns keys of the mapping in order of their appearance, resolving "<<" merge keys
+func collectYAMLMapping(node *yaml.Node) ([]string, map[string]*yaml.Node, error) {
+	var keys []string
+	values := make(map[string]*yaml.Node)
+	set := func(key string, value *yaml.Node) {
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Kind != yaml.ScalarNode {
+			return nil, nil, errors.Errorf("line %d: mapping keys must be scalars", keyNode.Line)
+		}
+		if keyNode.ShortTag() != "!!merge" {
+			continue
+		}
+
+		merged := []*yaml.Node{valueNode}
+		if valueNode.Kind == yaml.SequenceNode {
+			merged = valueNode.Content
+		}
+		for _, m := range merged {
+			if m.Kind == yaml.AliasNode {
+				m = m.Alias
+			}
+			if m.Kind != yaml.MappingNode {
+				return nil, nil, errors.Errorf("line %d: only mappings can be merged", m.Line)
+			}
+			mergedKeys, mergedValues, err := collectYAMLMapping(m)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, key := range mergedKeys {
+				if _, ok := values[key]; !ok {
+					set(key, mergedValues[key])
+				}
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.ShortTag() != "!!merge" {
+			set(keyNode.Value, valueNode)
+		}
+	}
+
+	return keys, values, nil
+}
+
+func writeYAMLScalarAsJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!null":
+		buf.WriteString("null")
+		return nil
+	case "!!int", "!!float", "!!bool":
+		if isJSONNumberOrBool(node.Value) {
+			buf.WriteString(node.Value)
+			return nil
+		}
+
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return errors.Wrapf(err, "line %d: cannot convert '%s' to JSON", node.Line, node.Value)
+		}
+		buf.Write(encoded)
+		return nil
+	default:
+		encoded, _ := json.Marshal(node.Value)
+		buf.Write(encoded)
+		return nil
+	}
+}
+
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func isJSONNumberOrBool(value string) bool {
+	return value == "true" || value == "false" || jsonNumber.MatchString(value)
+}
@@ -0,0 +1,188 @@
+package dynamotest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/eps90/dynamotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYamlMigrationDecoder(t *testing.T) {
+	decoder := new(dynamotest.YAMLMigrationDecoder)
+	input := definition("migrations/tableName.yml", "tableName", string(createSampleMigrationYAML()))
+	expected := []*dynamotest.TableDefinition{createSampleTableDefinition()}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestYamlMigrationDecoderMultipleDocuments(t *testing.T) {
+	decoder := new(dynamotest.YAMLMigrationDecoder)
+	contents := string(createSampleMigrationYAML()) + "---\n" +
+		"TableName: otherTable\n" +
+		"KeySchema:\n" +
+		"  - {AttributeName: ID, KeyType: HASH}\n" +
+		"---\n"
+	input := definition("migrations/tables.yml", "tables", contents)
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+	require.Equal(t, createSampleTableDefinition(), actual[0])
+	require.Equal(t, "otherTable", aws.StringValue(actual[1].TableName))
+}
+
+func TestYamlMigrationDecoderInvalidInput(t *testing.T) {
+	decoder := new(dynamotest.YAMLMigrationDecoder)
+	input := definition("migrations/tableName.yml", "tableName", "TableName: tableName\nKeySchema: [\n")
+
+	_, err := decoder.Decode(input)
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, input.Source, decodeErr.Source)
+	require.Equal(t, 2, decodeErr.Line)
+	require.Contains(t, err.Error(), "migrations/tableName.yml:2: cannot parse migration file")
+}
+
+func TestYamlMigrationDecoderTypeErrorPointsAtDocument(t *testing.T) {
+	decoder := new(dynamotest.YAMLMigrationDecoder)
+	contents := string(createSampleMigrationYAML()) + "---\nTableName: otherTable\nKeySchema: 5\n"
+	input := definition("migrations/tables.yml", "tables", contents)
+
+	_, err := decoder.Decode(input)
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, 12, decodeErr.Line)
+}
+
+func TestYamlFixturesDecoderRejectsLocalTags(t *testing.T) {
+	decoder := dynamotest.NewYAMLFixturesDecoder()
+	input := definition("fixtures/fixture0.yaml", "fixture0", "table: tableName\nitems:\n  - {ID: 1, Name: !Ref Name}\n")
+
+	_, err := decoder.Decode([]dynamotest.Definition{input})
+
+	var decodeErr *dynamotest.DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Contains(t, err.Error(), "line 3: unsupported tag '!Ref'")
+}
+
+func TestYamlFixturesDecoder(t *testing.T) {
+	decoder := dynamotest.NewYAMLFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/fixture0.yaml", "fixture0", "table: tableName\nitems:\n  - {ID: 1, Name: Abc}\n  - {ID: 2, Name: Bca}\n"),
+		definition("fixtures/fixture1.yaml", "fixture1", string(createSampleFixturesYAML())),
+	}
+	expected := createSampleWriteRequestMap()
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestYamlFixturesDecoderDoesNotWriteAliases(t *testing.T) {
+	decoder := dynamotest.NewYAMLFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/users.yaml", "users", "table: tableName\nitems:\n  - {$id: alice, ID: 1}\n"),
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, marshalMap(map[string]interface{}{"ID": 1}), actual["tableName"][0].PutRequest.Item)
+}
+
+func TestYamlFixturesDecoderResolvesAnchorsAndMergeKeys(t *testing.T) {
+	decoder := dynamotest.NewYAMLFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/pets.yml", "pets", `
+table: pets
+items:
+  - &cat
+    ID: 1
+    Kind: cat
+  - <<: *cat
+    ID: 2
+`),
+	}
+	expected := dynamotest.TableWriteRequests{
+		"pets": {
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID":   {N: aws.String("1")},
+				"Kind": {S: aws.String("cat")},
+			}}},
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID":   {N: aws.String("2")},
+				"Kind": {S: aws.String("cat")},
+			}}},
+		},
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestYamlFixturesDecoderPreservesNumbersPrecision(t *testing.T) {
+	decoder := dynamotest.NewYAMLFixturesDecoder()
+	input := []dynamotest.Definition{
+		definition("fixtures/numbers.yml", "numbers", `
+table: tableName
+items:
+  - ID: 12345678901234567890123456789012345678
+    Price: 0.12345678901234567890123456789012345678
+`),
+	}
+	expected := dynamotest.TableWriteRequests{
+		"tableName": {
+			{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+				"ID":    {N: aws.String("12345678901234567890123456789012345678")},
+				"Price": {N: aws.String("0.12345678901234567890123456789012345678")},
+			}}},
+		},
+	}
+
+	actual, err := decoder.Decode(input)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func createSampleMigrationYAML() []byte {
+	return []byte(`TableName: tableName
+AttributeDefinitions:
+  - AttributeName: ID
+    AttributeType: N
+KeySchema:
+  - AttributeName: ID
+    KeyType: HASH
+ProvisionedThroughput:
+  ReadCapacityUnits: 5
+  WriteCapacityUnits: 10
+`)
+}
+
+func createSampleFixturesYAML() []byte {
+	return []byte(`table: tableName
+items:
+  - ID: 5
+    Nested:
+      Name: BBB
+      Price: 200
+      CreatedAt: "2019-01-05T12:13:56Z"
+---
+table: otherTable
+items:
+  - ID: 7
+    Name: CCC
+`)
+}